@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"sync"
 	"time"
 
 	sdkmath "cosmossdk.io/math"
@@ -17,13 +18,19 @@ import (
 	bbnclient "github.com/babylonchain/rpc-client/client"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	sdkclient "github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkquery "github.com/cosmos/cosmos-sdk/types/query"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	"github.com/cosmos/relayer/v2/relayer/provider"
 	"go.uber.org/zap"
 
+	covcodec "github.com/babylonchain/covenant-emulator/codec"
 	"github.com/babylonchain/covenant-emulator/config"
 	"github.com/babylonchain/covenant-emulator/types"
 )
@@ -31,10 +38,18 @@ import (
 var _ ClientController = &BabylonController{}
 
 type BabylonController struct {
+	// clientMu guards bbnClient, which is replaced upon Reconnect, so it is
+	// read through client
+	clientMu  sync.RWMutex
 	bbnClient *bbnclient.Client
 	cfg       *config.BBNConfig
 	btcParams *chaincfg.Params
 	logger    *zap.Logger
+
+	// seqMu guards nextSeq, the sequence of the next tx of the submitter
+	// account if txs are broadcast but not yet included
+	seqMu   sync.Mutex
+	nextSeq uint64
 }
 
 func NewBabylonController(
@@ -57,11 +72,36 @@ func NewBabylonController(
 		return nil, fmt.Errorf("failed to create Babylon client: %w", err)
 	}
 
+	keyRec, err := bc.GetKeyring().Key(cfg.TxSignerKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the key %s to submit transactions: %w", cfg.TxSignerKey(), err)
+	}
+	addr, err := keyRec.GetAddress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the address of the key %s: %w", cfg.TxSignerKey(), err)
+	}
+	signer, err := sdk.Bech32ifyAddressBytes(cfg.AccountPrefix, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the address of the key %s: %w", cfg.TxSignerKey(), err)
+	}
+	logger.Info("using the Babylon account to submit transactions and pay fees",
+		zap.String("key", cfg.TxSignerKey()),
+		zap.String("address", signer),
+		zap.Bool("separate_from_covenant_key", cfg.TxSignerKey() != cfg.Key),
+	)
+
+	logger.Info("using fee settings for submitting transactions to Babylon",
+		zap.String("gas_prices", cfg.GasPrices),
+		zap.Float64("gas_adjustment", cfg.GasAdjustment),
+		zap.String("fee_granter", cfg.FeeGranter),
+		zap.String("submission_memo", cfg.SubmissionMemo),
+	)
+
 	return &BabylonController{
-		bc,
-		cfg,
-		btcParams,
-		logger,
+		bbnClient: bc,
+		cfg:       cfg,
+		btcParams: btcParams,
+		logger:    logger,
 	}, nil
 }
 
@@ -77,7 +117,7 @@ func (bc *BabylonController) GetKeyAddress() sdk.AccAddress {
 	// and we should panic.
 	// This is checked at the start of BabylonController, so if it fails something is really wrong
 
-	keyRec, err := bc.bbnClient.GetKeyring().Key(bc.cfg.Key)
+	keyRec, err := bc.client().GetKeyring().Key(bc.cfg.TxSignerKey())
 
 	if err != nil {
 		panic(fmt.Sprintf("Failed to get key address: %s", err))
@@ -94,13 +134,13 @@ func (bc *BabylonController) GetKeyAddress() sdk.AccAddress {
 
 func (bc *BabylonController) QueryStakingParams() (*types.StakingParams, error) {
 	// query btc checkpoint params
-	ckptParamRes, err := bc.bbnClient.QueryClient.BTCCheckpointParams()
+	ckptParamRes, err := bc.client().QueryClient.BTCCheckpointParams()
 	if err != nil {
 		return nil, fmt.Errorf("failed to query params of the btccheckpoint module: %v", err)
 	}
 
 	// query btc staking params
-	stakingParamRes, err := bc.bbnClient.QueryClient.BTCStakingParams()
+	stakingParamRes, err := bc.client().QueryClient.BTCStakingParams()
 	if err != nil {
 		return nil, fmt.Errorf("failed to query staking params: %v", err)
 	}
@@ -115,7 +155,7 @@ func (bc *BabylonController) QueryStakingParams() (*types.StakingParams, error)
 	}
 	slashingAddress, err := btcutil.DecodeAddress(stakingParamRes.Params.SlashingAddress, bc.btcParams)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidSlashingAddress, stakingParamRes.Params.SlashingAddress, err)
 	}
 
 	return &types.StakingParams{
@@ -131,12 +171,21 @@ func (bc *BabylonController) QueryStakingParams() (*types.StakingParams, error)
 	}, nil
 }
 
+func (bc *BabylonController) QueryBtcLightClientTipHeight() (uint64, error) {
+	tip, err := bc.QueryBtcLightClientTip()
+	if err != nil {
+		return 0, err
+	}
+
+	return tip.Height, nil
+}
+
 func (bc *BabylonController) reliablySendMsg(msg sdk.Msg) (*provider.RelayerTxResponse, error) {
 	return bc.reliablySendMsgs([]sdk.Msg{msg})
 }
 
 func (bc *BabylonController) reliablySendMsgs(msgs []sdk.Msg) (*provider.RelayerTxResponse, error) {
-	return bc.bbnClient.ReliablySendMsgs(
+	return bc.client().ReliablySendMsgs(
 		context.Background(),
 		msgs,
 		expectedErrors,
@@ -144,14 +193,15 @@ func (bc *BabylonController) reliablySendMsgs(msgs []sdk.Msg) (*provider.Relayer
 	)
 }
 
-// SubmitCovenantSigs submits the Covenant signature via a MsgAddCovenantSig to Babylon if the daemon runs in Covenant mode
-// it returns tx hash and error
-func (bc *BabylonController) SubmitCovenantSigs(covSigs []*types.CovenantSigs) (*types.TxResponse, error) {
+// buildCovenantSigsMsgs builds one MsgAddCovenantSigs per delegation, signed
+// by the submitter account
+func (bc *BabylonController) buildCovenantSigsMsgs(covSigs []*types.CovenantSigs) []sdk.Msg {
+	signer := bc.mustGetTxSigner()
 	msgs := make([]sdk.Msg, 0, len(covSigs))
 	for _, covSig := range covSigs {
 		bip340UnbondingSig := bbntypes.NewBIP340SignatureFromBTCSig(covSig.UnbondingSig)
 		msgs = append(msgs, &btcstakingtypes.MsgAddCovenantSigs{
-			Signer:                  bc.mustGetTxSigner(),
+			Signer:                  signer,
 			Pk:                      bbntypes.NewBIP340PubKeyFromBTCPK(covSig.PublicKey),
 			StakingTxHash:           covSig.StakingTxHash.String(),
 			SlashingTxSigs:          covSig.SlashingSigs,
@@ -159,33 +209,197 @@ func (bc *BabylonController) SubmitCovenantSigs(covSigs []*types.CovenantSigs) (
 			SlashingUnbondingTxSigs: covSig.SlashingUnbondingSigs,
 		})
 	}
-	res, err := bc.reliablySendMsgs(msgs)
+
+	return msgs
+}
+
+// BuildCovenantSigsTx builds the tx submitting the given Covenant signatures
+// without signing it by the submitter account. The fee is derived from the
+// configured gas prices and the given gas limit, as the gas cannot be simulated
+// offline, and the configured fee granter and submission memo are attached.
+// The tx is returned JSON encoded, in the format accepted by `babylond tx sign`
+// and `babylond tx broadcast`.
+func (bc *BabylonController) BuildCovenantSigsTx(covSigs []*types.CovenantSigs, gasLimit uint64) ([]byte, error) {
+	if gasLimit == 0 {
+		return nil, fmt.Errorf("the gas limit of the tx must be positive")
+	}
+
+	fees, err := bc.feesForGas(gasLimit)
+	if err != nil {
+		return nil, err
+	}
+	feeGranter, err := bc.cfg.FeeGranterAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	txConfig := authtx.NewTxConfig(covcodec.MakeCodec(), authtx.DefaultSignModes)
+	txBuilder := txConfig.NewTxBuilder()
+	if err := txBuilder.SetMsgs(bc.buildCovenantSigsMsgs(covSigs)...); err != nil {
+		return nil, fmt.Errorf("failed to set the msgs of the tx: %w", err)
+	}
+	txBuilder.SetGasLimit(gasLimit)
+	txBuilder.SetMemo(bc.cfg.SubmissionMemo)
+	txBuilder.SetFeeAmount(fees)
+	txBuilder.SetFeeGranter(feeGranter)
+
+	txJSON, err := txConfig.TxJSONEncoder()(txBuilder.GetTx())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the tx: %w", err)
+	}
+
+	return txJSON, nil
+}
+
+// EstimateCovenantSigsFee simulates the tx submitting the given Covenant
+// signatures without broadcasting it. It returns the gas that the tx is expected
+// to consume, multiplied by the configured gas adjustment as for submissions,
+// and the fee of that gas at the configured gas prices.
+func (bc *BabylonController) EstimateCovenantSigsFee(covSigs []*types.CovenantSigs) (uint64, string, error) {
+	keyRec, err := bc.client().GetKeyring().Key(bc.cfg.TxSignerKey())
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get the submitter key: %w", err)
+	}
+	pubKey, err := keyRec.GetPubKey()
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get the pk of the submitter key: %w", err)
+	}
+
+	feeGranter, err := bc.cfg.FeeGranterAddress()
+	if err != nil {
+		return 0, "", err
+	}
+
+	ctx, cancel := getContextWithCancel(bc.cfg.Timeout)
+	defer cancel()
+
+	clientCtx := bc.clientContext()
+
+	accountRes, err := authtypes.NewQueryClient(clientCtx).Account(ctx, &authtypes.QueryAccountRequest{
+		Address: bc.mustGetTxSigner(),
+	})
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to query the submitter account: %w", err)
+	}
+	account, err := unpackAccount(clientCtx.InterfaceRegistry, accountRes.Account)
 	if err != nil {
+		return 0, "", fmt.Errorf("failed to decode the submitter account: %w", err)
+	}
+
+	txConfig := clientCtx.TxConfig
+	txBuilder := txConfig.NewTxBuilder()
+	if err := txBuilder.SetMsgs(bc.buildCovenantSigsMsgs(covSigs)...); err != nil {
+		return 0, "", fmt.Errorf("failed to set the msgs of the tx: %w", err)
+	}
+	txBuilder.SetMemo(bc.cfg.SubmissionMemo)
+	txBuilder.SetFeeGranter(feeGranter)
+	// the signature is not verified in simulation, but the signer info is
+	// needed for the ante handlers to charge for it
+	if err := txBuilder.SetSignatures(signing.SignatureV2{
+		PubKey:   pubKey,
+		Data:     &signing.SingleSignatureData{SignMode: signing.SignMode_SIGN_MODE_DIRECT},
+		Sequence: account.GetSequence(),
+	}); err != nil {
+		return 0, "", fmt.Errorf("failed to set the signer of the tx: %w", err)
+	}
+	txBytes, err := txConfig.TxEncoder()(txBuilder.GetTx())
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to encode the tx: %w", err)
+	}
+
+	simRes, err := txtypes.NewServiceClient(clientCtx).Simulate(ctx, &txtypes.SimulateRequest{TxBytes: txBytes})
+	if err != nil {
+		if isDelegationNotFound(err) {
+			return 0, "", fmt.Errorf("%w: %v", ErrDelegationNotFound, err)
+		}
+		return 0, "", fmt.Errorf("failed to simulate the tx: %w", err)
+	}
+
+	gas := uint64(bc.cfg.GasAdjustment * float64(simRes.GasInfo.GasUsed))
+	fees, err := bc.feesForGas(gas)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return gas, fees.String(), nil
+}
+
+// feesForGas returns the fee of the given amount of gas at the configured gas prices
+func (bc *BabylonController) feesForGas(gasLimit uint64) (sdk.Coins, error) {
+	gasPrices, err := sdk.ParseDecCoins(bc.cfg.GasPrices)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gas prices %s: %w", bc.cfg.GasPrices, err)
+	}
+	fees := make(sdk.Coins, 0, len(gasPrices))
+	gas := sdkmath.LegacyNewDecFromInt(sdkmath.NewIntFromUint64(gasLimit))
+	for _, gasPrice := range gasPrices {
+		fees = append(fees, sdk.NewCoin(gasPrice.Denom, gasPrice.Amount.Mul(gas).Ceil().RoundInt()))
+	}
+
+	return fees.Sort(), nil
+}
+
+// SubmitCovenantSigs submits the Covenant signature via a MsgAddCovenantSig to Babylon if the daemon runs in Covenant mode
+// the tx carries the configured submission memo
+// it returns tx hash and error
+func (bc *BabylonController) SubmitCovenantSigs(ctx context.Context, covSigs []*types.CovenantSigs) (*types.TxResponse, error) {
+	res, err := bc.sendCovenantSigsTx(ctx, bc.buildCovenantSigsMsgs(covSigs))
+	if err != nil {
+		if isDelegationNotFound(err) {
+			return nil, fmt.Errorf("%w: %v", ErrDelegationNotFound, err)
+		}
 		return nil, err
 	}
 
-	return &types.TxResponse{TxHash: res.TxHash, Events: res.Events}, nil
+	return res, nil
+}
+
+func (bc *BabylonController) QueryPendingDelegations(ctx context.Context, limit uint64) ([]*types.Delegation, error) {
+	return bc.queryDelegationsWithStatus(ctx, btcstakingtypes.BTCDelegationStatus_PENDING, limit)
 }
 
-func (bc *BabylonController) QueryPendingDelegations(limit uint64) ([]*types.Delegation, error) {
-	return bc.queryDelegationsWithStatus(btcstakingtypes.BTCDelegationStatus_PENDING, limit)
+func (bc *BabylonController) QueryPendingDelegationsPage(ctx context.Context, limit uint64, pageKey []byte) ([]*types.Delegation, []byte, error) {
+	return bc.queryDelegationsPage(ctx, btcstakingtypes.BTCDelegationStatus_PENDING, limit, pageKey)
 }
 
 func (bc *BabylonController) QueryActiveDelegations(limit uint64) ([]*types.Delegation, error) {
-	return bc.queryDelegationsWithStatus(btcstakingtypes.BTCDelegationStatus_ACTIVE, limit)
+	return bc.queryDelegationsWithStatus(context.Background(), btcstakingtypes.BTCDelegationStatus_ACTIVE, limit)
 }
 
 // queryDelegationsWithStatus queries BTC delegations that need a Covenant signature
 // with the given status (either pending or unbonding)
 // it is only used when the program is running in Covenant mode
-func (bc *BabylonController) queryDelegationsWithStatus(status btcstakingtypes.BTCDelegationStatus, limit uint64) ([]*types.Delegation, error) {
+func (bc *BabylonController) queryDelegationsWithStatus(ctx context.Context, status btcstakingtypes.BTCDelegationStatus, limit uint64) ([]*types.Delegation, error) {
+	dels, _, err := bc.queryDelegationsPage(ctx, status, limit, nil)
+	return dels, err
+}
+
+// queryDelegationsPage queries a page of BTC delegations with the given status
+// starting at the given page key and returns the key of the next page. The
+// query is cancelled once the given context is done or the configured timeout
+// passes.
+func (bc *BabylonController) queryDelegationsPage(
+	ctx context.Context,
+	status btcstakingtypes.BTCDelegationStatus,
+	limit uint64,
+	pageKey []byte,
+) ([]*types.Delegation, []byte, error) {
 	pagination := &sdkquery.PageRequest{
+		Key:   pageKey,
 		Limit: limit,
 	}
 
-	res, err := bc.bbnClient.QueryClient.BTCDelegations(status, pagination)
+	ctx, cancel := context.WithTimeout(ctx, bc.cfg.Timeout)
+	defer cancel()
+
+	clientCtx := sdkclient.Context{Client: bc.client().RPCClient}
+	queryClient := btcstakingtypes.NewQueryClient(clientCtx)
+	res, err := queryClient.BTCDelegations(ctx, &btcstakingtypes.QueryBTCDelegationsRequest{
+		Status:     status,
+		Pagination: pagination,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to query BTC delegations: %v", err)
+		return nil, nil, fmt.Errorf("failed to query BTC delegations: %v", err)
 	}
 
 	dels := make([]*types.Delegation, 0, len(res.BtcDelegations))
@@ -193,7 +407,49 @@ func (bc *BabylonController) queryDelegationsWithStatus(status btcstakingtypes.B
 		dels = append(dels, ConvertDelegationType(d))
 	}
 
-	return dels, nil
+	var nextKey []byte
+	if res.Pagination != nil {
+		nextKey = res.Pagination.NextKey
+	}
+
+	return dels, nextKey, nil
+}
+
+// QueryBTCDelegation queries the BTC delegation with the given staking tx hash.
+// Note that the query does not return the slashing txs of the delegation, so
+// the returned delegation is only meant for checking its covenant signatures.
+func (bc *BabylonController) QueryBTCDelegation(stakingTxHash chainhash.Hash) (*types.Delegation, error) {
+	res, err := bc.client().QueryClient.BTCDelegation(stakingTxHash.String())
+	if err != nil {
+		if isDelegationNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", ErrDelegationNotFound, stakingTxHash.String())
+		}
+		return nil, fmt.Errorf("failed to query BTC delegation %s: %v", stakingTxHash.String(), err)
+	}
+
+	fpBtcPks := make([]*btcec.PublicKey, 0, len(res.FpBtcPkList))
+	for _, fp := range res.FpBtcPkList {
+		fpBtcPks = append(fpBtcPks, fp.MustToBTCPK())
+	}
+
+	undelegation := &types.Undelegation{}
+	if res.UndelegationInfo != nil {
+		undelegation.UnbondingTxHex = hex.EncodeToString(res.UndelegationInfo.UnbondingTx)
+		undelegation.CovenantSlashingSigs = convertCovenantAdaptorSigs(res.UndelegationInfo.CovenantSlashingSigs)
+		undelegation.CovenantUnbondingSigs = convertCovenantSchnorrSigs(res.UndelegationInfo.CovenantUnbondingSigList)
+		undelegation.DelegatorUnbondingSig = res.UndelegationInfo.DelegatorUnbondingSig
+	}
+
+	return &types.Delegation{
+		BtcPk:           res.BtcPk.MustToBTCPK(),
+		FpBtcPks:        fpBtcPks,
+		TotalSat:        res.TotalSat,
+		StartHeight:     res.StartHeight,
+		EndHeight:       res.EndHeight,
+		StakingTxHex:    res.StakingTxHex,
+		CovenantSigs:    convertCovenantAdaptorSigs(res.CovenantSigs),
+		BtcUndelegation: undelegation,
+	}, nil
 }
 
 func getContextWithCancel(timeout time.Duration) (context.Context, context.CancelFunc) {
@@ -201,12 +457,50 @@ func getContextWithCancel(timeout time.Duration) (context.Context, context.Cance
 	return ctx, cancel
 }
 
+// client returns the current Babylon client
+func (bc *BabylonController) client() *bbnclient.Client {
+	bc.clientMu.RLock()
+	defer bc.clientMu.RUnlock()
+
+	return bc.bbnClient
+}
+
+// Reconnect creates a new Babylon client from the config, which replaces the
+// current one, and stops the current one. The calls in flight on the current
+// client may fail as it is stopped.
+func (bc *BabylonController) Reconnect() error {
+	bbnConfig := config.BBNConfigToBabylonConfig(bc.cfg)
+
+	newClient, err := bbnclient.New(
+		&bbnConfig,
+		bc.logger,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create Babylon client: %w", err)
+	}
+
+	bc.clientMu.Lock()
+	prevClient := bc.bbnClient
+	bc.bbnClient = newClient
+	bc.clientMu.Unlock()
+
+	if err := stopClient(prevClient); err != nil {
+		bc.logger.Debug("failed to stop the previous Babylon client", zap.Error(err))
+	}
+
+	return nil
+}
+
 func (bc *BabylonController) Close() error {
-	if !bc.bbnClient.IsRunning() {
+	return stopClient(bc.client())
+}
+
+func stopClient(c *bbnclient.Client) error {
+	if !c.IsRunning() {
 		return nil
 	}
 
-	return bc.bbnClient.Stop()
+	return c.Stop()
 }
 
 func ConvertDelegationType(del *btcstakingtypes.BTCDelegation) *types.Delegation {
@@ -229,13 +523,7 @@ func ConvertDelegationType(del *btcstakingtypes.BTCDelegation) *types.Delegation
 
 	slashingTxHex = del.SlashingTx.ToHexStr()
 
-	for _, s := range del.CovenantSigs {
-		covSigInfo := &types.CovenantAdaptorSigInfo{
-			Pk:   s.CovPk.MustToBTCPK(),
-			Sigs: s.AdaptorSigs,
-		}
-		covenantSigs = append(covenantSigs, covSigInfo)
-	}
+	covenantSigs = convertCovenantAdaptorSigs(del.CovenantSigs)
 
 	if del.BtcUndelegation != nil {
 		undelegation = ConvertUndelegationType(del.BtcUndelegation)
@@ -280,7 +568,35 @@ func ConvertUndelegationType(undel *btcstakingtypes.BTCUndelegation) *types.Unde
 
 	slashingTxHex = undel.SlashingTx.ToHexStr()
 
-	for _, unbondingSig := range undel.CovenantUnbondingSigList {
+	covenantUnbondingSigs = convertCovenantSchnorrSigs(undel.CovenantUnbondingSigList)
+
+	covenantSlashingSigs = convertCovenantAdaptorSigs(undel.CovenantSlashingSigs)
+
+	return &types.Undelegation{
+		UnbondingTxHex:        unbondingTxHex,
+		SlashingTxHex:         slashingTxHex,
+		CovenantSlashingSigs:  covenantSlashingSigs,
+		CovenantUnbondingSigs: covenantUnbondingSigs,
+		DelegatorUnbondingSig: undel.DelegatorUnbondingSig,
+	}
+}
+
+func convertCovenantAdaptorSigs(sigs []*btcstakingtypes.CovenantAdaptorSignatures) []*types.CovenantAdaptorSigInfo {
+	var covenantSigs []*types.CovenantAdaptorSigInfo
+	for _, s := range sigs {
+		covSigInfo := &types.CovenantAdaptorSigInfo{
+			Pk:   s.CovPk.MustToBTCPK(),
+			Sigs: s.AdaptorSigs,
+		}
+		covenantSigs = append(covenantSigs, covSigInfo)
+	}
+
+	return covenantSigs
+}
+
+func convertCovenantSchnorrSigs(sigs []*btcstakingtypes.SignatureInfo) []*types.CovenantSchnorrSigInfo {
+	var covenantSigs []*types.CovenantSchnorrSigInfo
+	for _, unbondingSig := range sigs {
 		sig, err := unbondingSig.Sig.ToBTCSig()
 		if err != nil {
 			panic(err)
@@ -289,24 +605,10 @@ func ConvertUndelegationType(undel *btcstakingtypes.BTCUndelegation) *types.Unde
 			Pk:  unbondingSig.Pk.MustToBTCPK(),
 			Sig: sig,
 		}
-		covenantUnbondingSigs = append(covenantUnbondingSigs, sigInfo)
+		covenantSigs = append(covenantSigs, sigInfo)
 	}
 
-	for _, s := range undel.CovenantSlashingSigs {
-		covSigInfo := &types.CovenantAdaptorSigInfo{
-			Pk:   s.CovPk.MustToBTCPK(),
-			Sigs: s.AdaptorSigs,
-		}
-		covenantSlashingSigs = append(covenantSlashingSigs, covSigInfo)
-	}
-
-	return &types.Undelegation{
-		UnbondingTxHex:        unbondingTxHex,
-		SlashingTxHex:         slashingTxHex,
-		CovenantSlashingSigs:  covenantSlashingSigs,
-		CovenantUnbondingSigs: covenantUnbondingSigs,
-		DelegatorUnbondingSig: undel.DelegatorUnbondingSig,
-	}
+	return covenantSigs
 }
 
 // Currently this is only used for e2e tests, probably does not need to add it into the interface
@@ -400,7 +702,7 @@ func (bc *BabylonController) QueryFinalityProviders() ([]*btcstakingtypes.Finali
 	ctx, cancel := getContextWithCancel(bc.cfg.Timeout)
 	defer cancel()
 
-	clientCtx := sdkclient.Context{Client: bc.bbnClient.RPCClient}
+	clientCtx := sdkclient.Context{Client: bc.client().RPCClient}
 
 	queryClient := btcstakingtypes.NewQueryClient(clientCtx)
 
@@ -428,7 +730,7 @@ func (bc *BabylonController) QueryBtcLightClientTip() (*btclctypes.BTCHeaderInfo
 	ctx, cancel := getContextWithCancel(bc.cfg.Timeout)
 	defer cancel()
 
-	clientCtx := sdkclient.Context{Client: bc.bbnClient.RPCClient}
+	clientCtx := sdkclient.Context{Client: bc.client().RPCClient}
 
 	queryClient := btclctypes.NewQueryClient(clientCtx)
 
@@ -1,8 +1,12 @@
 package clientcontroller
 
 import (
+	"context"
+	"errors"
 	"fmt"
+
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"go.uber.org/zap"
 
 	"github.com/babylonchain/covenant-emulator/config"
@@ -13,17 +17,51 @@ const (
 	babylonConsumerChainName = "babylon"
 )
 
+// ErrDelegationNotFound is returned when the queried or signed BTC delegation
+// does not exist on the consumer chain, e.g., because it is withdrawn
+var ErrDelegationNotFound = errors.New("the BTC delegation is not found")
+
+// ErrInvalidSlashingAddress is returned when the slashing address in the
+// staking params is empty or cannot be decoded
+var ErrInvalidSlashingAddress = errors.New("invalid slashing address in the staking params")
+
 type ClientController interface {
 	// SubmitCovenantSigs submits Covenant signatures to the consumer chain, each corresponding to
 	// a finality provider that the delegation is (re-)staked to
-	// it returns tx hash and error
-	SubmitCovenantSigs(covSigMsgs []*types.CovenantSigs) (*types.TxResponse, error)
+	// it returns tx hash and error, and stops waiting for the tx to be included once ctx is done
+	SubmitCovenantSigs(ctx context.Context, covSigMsgs []*types.CovenantSigs) (*types.TxResponse, error)
+
+	// BuildCovenantSigsTx builds the tx submitting the given Covenant signatures without
+	// signing it by the submitter account, so that it can be signed and broadcast elsewhere
+	// it returns the JSON encoded unsigned tx
+	BuildCovenantSigsTx(covSigMsgs []*types.CovenantSigs, gasLimit uint64) ([]byte, error)
+
+	// EstimateCovenantSigsFee simulates the tx submitting the given Covenant signatures
+	// without broadcasting it, it returns the expected gas and the fee of it
+	EstimateCovenantSigsFee(covSigMsgs []*types.CovenantSigs) (uint64, string, error)
 
 	// QueryPendingDelegations queries BTC delegations that are in status of pending
-	QueryPendingDelegations(limit uint64) ([]*types.Delegation, error)
+	// the query is cancelled once ctx is done
+	QueryPendingDelegations(ctx context.Context, limit uint64) ([]*types.Delegation, error)
+
+	// QueryPendingDelegationsPage queries a page of at most limit pending BTC delegations
+	// starting at the given page key, or at the first page if the key is empty
+	// it returns the key of the next page, which is empty if it is the last page
+	// the query is cancelled once ctx is done
+	QueryPendingDelegationsPage(ctx context.Context, limit uint64, pageKey []byte) ([]*types.Delegation, []byte, error)
+
+	// QueryBTCDelegation queries the BTC delegation with the given staking tx hash
+	QueryBTCDelegation(stakingTxHash chainhash.Hash) (*types.Delegation, error)
 
 	QueryStakingParams() (*types.StakingParams, error)
 
+	// QueryBtcLightClientTipHeight queries the height of the BTC light client tip
+	QueryBtcLightClientTipHeight() (uint64, error)
+
+	// Reconnect re-creates the underlying connection to the consumer chain
+	// the calls in flight on the previous connection may fail as it is closed
+	Reconnect() error
+
 	Close() error
 }
 
@@ -1,7 +1,11 @@
 package clientcontroller
 
 import (
+	"strings"
+	"time"
+
 	sdkErr "cosmossdk.io/errors"
+	"github.com/avast/retry-go/v4"
 	btcstakingtypes "github.com/babylonchain/babylon/x/btcstaking/types"
 )
 
@@ -13,3 +17,31 @@ var unrecoverableErrors = []*sdkErr.Error{
 }
 
 var expectedErrors = []*sdkErr.Error{}
+
+// the retries of submitting txs, as in the rpc client
+var (
+	rtyAttNum = uint(5)
+	rtyAtt    = retry.Attempts(rtyAttNum)
+	rtyDel    = retry.Delay(time.Millisecond * 400)
+	rtyErr    = retry.LastErrorOnly(true)
+)
+
+// errorContained reports whether the error is one of the given errors. The
+// error is matched by its message as the registered error type is lost in the
+// tx responses.
+func errorContained(err error, errList []*sdkErr.Error) bool {
+	for _, e := range errList {
+		if strings.Contains(err.Error(), e.Error()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isDelegationNotFound reports whether the error is caused by the BTC delegation
+// not being found on the consumer chain. The error is matched by its message as
+// the registered error type is lost in the gRPC and tx responses.
+func isDelegationNotFound(err error) bool {
+	return strings.Contains(err.Error(), btcstakingtypes.ErrBTCDelegationNotFound.Error())
+}
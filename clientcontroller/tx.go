@@ -0,0 +1,292 @@
+package clientcontroller
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	sdkclient "github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/juju/fslock"
+	"go.uber.org/zap"
+
+	covcodec "github.com/babylonchain/covenant-emulator/codec"
+	"github.com/babylonchain/covenant-emulator/config"
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// txInclusionPollInterval is the interval of polling the consumer chain for
+// the inclusion of a broadcast tx
+const txInclusionPollInterval = time.Second
+
+// newCovenantSigsTxFactory returns the factory of the txs submitting Covenant
+// signatures, carrying the configured fee settings, fee granter and submission
+// memo
+func newCovenantSigsTxFactory(cfg *config.BBNConfig, txConfig sdkclient.TxConfig, kr keyring.Keyring) (tx.Factory, error) {
+	feeGranter, err := cfg.FeeGranterAddress()
+	if err != nil {
+		return tx.Factory{}, err
+	}
+
+	return tx.Factory{}.
+		WithTxConfig(txConfig).
+		WithKeybase(kr).
+		WithChainID(cfg.ChainID).
+		WithAccountRetriever(authtypes.AccountRetriever{}).
+		WithGasAdjustment(cfg.GasAdjustment).
+		WithGasPrices(cfg.GasPrices).
+		WithFeeGranter(feeGranter).
+		WithMemo(cfg.SubmissionMemo).
+		WithSignMode(signing.SignMode_SIGN_MODE_DIRECT), nil
+}
+
+// signTx builds the tx of the given msgs from the factory, signs it by the key
+// with the given name and returns the tx encoded by the given tx config
+func signTx(txf tx.Factory, txConfig sdkclient.TxConfig, keyName string, msgs []sdk.Msg) ([]byte, error) {
+	txBuilder, err := txf.BuildUnsignedTx(msgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the tx: %w", err)
+	}
+	if err := tx.Sign(context.Background(), txf, keyName, txBuilder, true); err != nil {
+		return nil, fmt.Errorf("failed to sign the tx: %w", err)
+	}
+
+	txBytes, err := txConfig.TxEncoder()(txBuilder.GetTx())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the tx: %w", err)
+	}
+
+	return txBytes, nil
+}
+
+// clientContext returns the context for querying the consumer chain and
+// broadcasting txs signed by the submitter account
+func (bc *BabylonController) clientContext() sdkclient.Context {
+	cdc := covcodec.MakeCodec()
+	bbnClient := bc.client()
+
+	return sdkclient.Context{}.
+		WithClient(bbnClient.RPCClient).
+		WithChainID(bc.cfg.ChainID).
+		WithCodec(cdc).
+		WithInterfaceRegistry(cdc.InterfaceRegistry()).
+		WithTxConfig(authtx.NewTxConfig(cdc, authtx.DefaultSignModes)).
+		WithAccountRetriever(authtypes.AccountRetriever{}).
+		WithKeyring(bbnClient.GetKeyring()).
+		WithFromName(bc.cfg.TxSignerKey()).
+		WithFromAddress(bc.GetKeyAddress()).
+		WithBroadcastMode("sync")
+}
+
+// unpackAccount decodes the given account through the given interface
+// registry, so that any kind of account can submit, e.g., a vesting one
+func unpackAccount(ir codectypes.InterfaceRegistry, accountAny *codectypes.Any) (sdk.AccountI, error) {
+	var account sdk.AccountI
+	if err := ir.UnpackAny(accountAny, &account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// sendCovenantSigsTx sends the tx of the given msgs like the rpc client's
+// ReliablySendMsgs, which cannot attach the submission memo. It retries the
+// broadcast unless the error is unrecoverable, returns no response if the
+// error is expected, and accesses the keyring while holding the keyring lock.
+// Once broadcast, it waits for the tx to be included until the given context
+// is done.
+func (bc *BabylonController) sendCovenantSigsTx(ctx context.Context, msgs []sdk.Msg) (*types.TxResponse, error) {
+	var txHash string
+	if err := retry.Do(func() error {
+		var sendErr error
+		lockErr := bc.accessKeyWithLock(func() {
+			txHash, sendErr = bc.broadcastCovenantSigsTx(msgs)
+		})
+		if lockErr != nil {
+			bc.logger.Error("unrecoverable err when submitting the tx, skip retrying", zap.Error(lockErr))
+			return retry.Unrecoverable(lockErr)
+		}
+		if sendErr != nil {
+			if errorContained(sendErr, unrecoverableErrors) {
+				bc.logger.Error("unrecoverable err when submitting the tx, skip retrying", zap.Error(sendErr))
+				return retry.Unrecoverable(sendErr)
+			}
+			if errorContained(sendErr, expectedErrors) {
+				bc.logger.Error("expected err when submitting the tx, skip retrying", zap.Error(sendErr))
+				txHash = ""
+				return nil
+			}
+			return sendErr
+		}
+		return nil
+	}, retry.Context(ctx), rtyAtt, rtyDel, rtyErr, retry.OnRetry(func(n uint, err error) {
+		bc.logger.Debug("retrying", zap.Uint("attempt", n+1), zap.Uint("max_attempts", rtyAttNum), zap.Error(err))
+	})); err != nil {
+		return nil, err
+	}
+	if txHash == "" {
+		return nil, nil
+	}
+
+	res, err := bc.waitForTx(ctx, txHash)
+	if err != nil {
+		if errorContained(err, expectedErrors) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// broadcastCovenantSigsTx signs the tx of the given msgs by the submitter
+// account through the Covenant signatures tx factory and broadcasts it,
+// returning its hash. The gas is simulated and multiplied by the configured gas
+// adjustment.
+func (bc *BabylonController) broadcastCovenantSigsTx(msgs []sdk.Msg) (string, error) {
+	clientCtx := bc.clientContext()
+
+	txf, err := newCovenantSigsTxFactory(bc.cfg, clientCtx.TxConfig, clientCtx.Keyring)
+	if err != nil {
+		return "", err
+	}
+	txf, err = txf.Prepare(clientCtx)
+	if err != nil {
+		return "", fmt.Errorf("failed to query the submitter account: %w", err)
+	}
+
+	// the queried sequence does not count the txs that are broadcast but not
+	// yet included, so the txs submitted concurrently are sequenced here
+	bc.seqMu.Lock()
+	defer bc.seqMu.Unlock()
+	if bc.nextSeq > txf.Sequence() {
+		txf = txf.WithSequence(bc.nextSeq)
+	}
+
+	_, gas, err := tx.CalculateGas(clientCtx, txf, msgs...)
+	if err != nil {
+		bc.resetSequenceOnMismatch(err)
+		return "", fmt.Errorf("failed to simulate the tx: %w", err)
+	}
+	txf = txf.WithGas(gas)
+
+	txBytes, err := signTx(txf, clientCtx.TxConfig, bc.cfg.TxSignerKey(), msgs)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := clientCtx.BroadcastTxSync(txBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to broadcast the tx: %w", err)
+	}
+	if res.Code != 0 {
+		err := fmt.Errorf("transaction failed with code %d: %s", res.Code, res.RawLog)
+		bc.resetSequenceOnMismatch(err)
+		return "", err
+	}
+	bc.nextSeq = txf.Sequence() + 1
+
+	return res.TxHash, nil
+}
+
+// resetSequenceOnMismatch makes the next tx use the queried sequence of the
+// submitter account if the given error is caused by a wrong sequence, e.g.,
+// because a broadcast tx is dropped from the mempool. It must be called while
+// holding seqMu.
+func (bc *BabylonController) resetSequenceOnMismatch(err error) {
+	if strings.Contains(err.Error(), sdkerrors.ErrWrongSequence.Error()) {
+		bc.nextSeq = 0
+	}
+}
+
+// accessKeyWithLock runs the given function, which accesses the keyring,
+// while holding the lock of the keyring shared with the rpc client, so that
+// the keyring is not accessed concurrently by other processes using it
+func (bc *BabylonController) accessKeyWithLock(accessFunc func()) error {
+	lockFilePath := path.Join(bc.cfg.KeyDirectory, "keys.lock")
+	lock := fslock.New(lockFilePath)
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("failed to acquire file system lock (%s): %w", lockFilePath, err)
+	}
+
+	accessFunc()
+
+	if err := lock.Unlock(); err != nil {
+		return fmt.Errorf("error unlocking file system lock (%s), please manually delete", lockFilePath)
+	}
+
+	return nil
+}
+
+// isTxNotFound reports whether the error of querying a tx is caused by the tx
+// not being included (yet)
+func isTxNotFound(err error) bool {
+	return strings.Contains(err.Error(), "not found")
+}
+
+// waitForTx polls the consumer chain for the tx with the given hash until it
+// is included, the configured block timeout passes or the given context is done
+func (bc *BabylonController) waitForTx(ctx context.Context, txHashHex string) (*types.TxResponse, error) {
+	txHash, err := hex.DecodeString(txHashHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tx hash: %w", err)
+	}
+
+	timeout := time.NewTimer(bc.cfg.BlockTimeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(txInclusionPollInterval)
+	defer ticker.Stop()
+	for {
+		resTx, err := bc.client().GetTx(txHash)
+		if err == nil {
+			if resTx.TxResult.Code != 0 {
+				return nil, fmt.Errorf("transaction %s failed with code %d: %s",
+					txHashHex, resTx.TxResult.Code, resTx.TxResult.Log)
+			}
+
+			events := make([]provider.RelayerEvent, 0, len(resTx.TxResult.Events))
+			for _, event := range resTx.TxResult.Events {
+				attributes := make(map[string]string, len(event.Attributes))
+				for _, attr := range event.Attributes {
+					attributes[attr.Key] = attr.Value
+				}
+				events = append(events, provider.RelayerEvent{
+					EventType:  event.Type,
+					Attributes: attributes,
+				})
+			}
+
+			return &types.TxResponse{
+				TxHash:    txHashHex,
+				Height:    resTx.Height,
+				Code:      resTx.TxResult.Code,
+				GasWanted: resTx.TxResult.GasWanted,
+				GasUsed:   resTx.TxResult.GasUsed,
+				Events:    events,
+			}, nil
+		}
+		if !isTxNotFound(err) {
+			return nil, fmt.Errorf("failed to query transaction %s: %w", txHashHex, err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-timeout.C:
+			return nil, fmt.Errorf("transaction %s is not included within %v", txHashHex, bc.cfg.BlockTimeout)
+		case <-ctx.Done():
+			return nil, fmt.Errorf("stopped waiting for transaction %s to be included: %w", txHashHex, ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package clientcontroller
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	btcstakingtypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	vestingtypes "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
+	"github.com/stretchr/testify/require"
+
+	covcodec "github.com/babylonchain/covenant-emulator/codec"
+	"github.com/babylonchain/covenant-emulator/config"
+)
+
+// signTestCovenantSigsTx signs a tx submitting Covenant signatures through the
+// factory built from the given config and decodes the broadcast tx
+func signTestCovenantSigsTx(t *testing.T, cfg *config.BBNConfig) (*txtypes.TxRaw, *txtypes.TxBody, *txtypes.AuthInfo) {
+	cdc := covcodec.MakeCodec()
+	txConfig := authtx.NewTxConfig(cdc, authtx.DefaultSignModes)
+	kr := keyring.NewInMemory(cdc)
+	keyName := "submitter"
+	_, _, err := kr.NewMnemonic(keyName, keyring.English, sdk.FullFundraiserPath, keyring.DefaultBIP39Passphrase, hd.Secp256k1)
+	require.NoError(t, err)
+
+	// the account number and sequence are set so that the factory does not
+	// need to query them
+	txf, err := newCovenantSigsTxFactory(cfg, txConfig, kr)
+	require.NoError(t, err)
+	txf = txf.
+		WithAccountNumber(1).
+		WithSequence(1).
+		WithGas(200000)
+
+	txBytes, err := signTx(txf, txConfig, keyName, []sdk.Msg{&btcstakingtypes.MsgAddCovenantSigs{}})
+	require.NoError(t, err)
+
+	var txRaw txtypes.TxRaw
+	require.NoError(t, txRaw.Unmarshal(txBytes))
+	var body txtypes.TxBody
+	require.NoError(t, body.Unmarshal(txRaw.BodyBytes))
+	var authInfo txtypes.AuthInfo
+	require.NoError(t, authInfo.Unmarshal(txRaw.AuthInfoBytes))
+
+	return &txRaw, &body, &authInfo
+}
+
+// TestCovenantSigsTxMemo checks that the submission memo is attached to the
+// signed tx that is broadcast
+func TestCovenantSigsTxMemo(t *testing.T) {
+	cfg := config.DefaultBBNConfig()
+	cfg.SubmissionMemo = "covenant-operator-1"
+
+	txRaw, body, _ := signTestCovenantSigsTx(t, &cfg)
+	require.Len(t, txRaw.Signatures, 1)
+	require.Equal(t, cfg.SubmissionMemo, body.Memo)
+}
+
+// TestCovenantSigsTxFeeGranter checks that the fee granter is set on the
+// signed tx that is broadcast
+func TestCovenantSigsTxFeeGranter(t *testing.T) {
+	cfg := config.DefaultBBNConfig()
+	_, _, authInfo := signTestCovenantSigsTx(t, &cfg)
+	require.Empty(t, authInfo.Fee.Granter)
+
+	granter := sdk.AccAddress([]byte("fee-granter-account"))
+	cfg.FeeGranter = sdk.MustBech32ifyAddressBytes(cfg.AccountPrefix, granter)
+	require.NoError(t, cfg.Validate())
+
+	// the granter is encoded with the global account prefix
+	_, _, authInfo = signTestCovenantSigsTx(t, &cfg)
+	require.Equal(t, granter.String(), authInfo.Fee.Granter)
+}
+
+// TestUnpackAccount checks that the submitter account is decoded whatever
+// kind of account it is
+func TestUnpackAccount(t *testing.T) {
+	cdc := covcodec.MakeCodec()
+	addr := sdk.AccAddress([]byte("submitter-account"))
+	baseAccount := authtypes.NewBaseAccount(addr, nil, 1, 7)
+	vestingAccount, err := vestingtypes.NewContinuousVestingAccount(baseAccount, sdk.NewCoins(sdk.NewInt64Coin("ubbn", 1)), 0, 1)
+	require.NoError(t, err)
+
+	for _, account := range []sdk.AccountI{
+		baseAccount,
+		vestingAccount,
+		authtypes.NewModuleAccount(baseAccount, "module"),
+	} {
+		accountAny, err := codectypes.NewAnyWithValue(account)
+		require.NoError(t, err)
+
+		unpacked, err := unpackAccount(cdc.InterfaceRegistry(), accountAny)
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), unpacked.GetSequence())
+	}
+}
+
+// TestSubmissionErrors checks the classification of the errors of submitting
+// a tx and of waiting for it to be included
+func TestSubmissionErrors(t *testing.T) {
+	invalidSigErr := fmt.Errorf("failed to simulate the tx: %s", btcstakingtypes.ErrInvalidCovenantSig.Error())
+	require.True(t, errorContained(invalidSigErr, unrecoverableErrors))
+	require.False(t, errorContained(errors.New("connection refused"), unrecoverableErrors))
+	require.False(t, errorContained(invalidSigErr, expectedErrors))
+
+	require.True(t, isTxNotFound(errors.New("tx (0A1B) not found")))
+	require.False(t, isTxNotFound(errors.New("post failed: connection refused")))
+}
@@ -10,9 +10,15 @@ const (
 	hdPathFlag         = "hd-path"
 	chainIdFlag        = "chain-id"
 	keyringBackendFlag = "keyring-backend"
+	fileFlag           = "file"
+	offlineTxFileFlag  = "offline-tx-file"
+	gasLimitFlag       = "gas-limit"
+	backfillFlag       = "backfill"
 
 	defaultChainID        = "chain-test"
 	defaultKeyringBackend = keyring.BackendTest
 	defaultPassphrase     = ""
 	defaultHdPath         = ""
+
+	defaultOfflineGasLimit = 500000
 )
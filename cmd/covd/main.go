@@ -16,7 +16,7 @@ func main() {
 	app := cli.NewApp()
 	app.Name = "covd"
 	app.Usage = "Covenant Emulator Daemon (covd)."
-	app.Commands = append(app.Commands, startCommand, initCommand, createKeyCommand)
+	app.Commands = append(app.Commands, startCommand, initCommand, createKeyCommand, signDelegationsCommand)
 
 	if err := app.Run(os.Args); err != nil {
 		fatal(err)
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli"
+
+	"github.com/babylonchain/covenant-emulator/clientcontroller"
+	covcfg "github.com/babylonchain/covenant-emulator/config"
+	"github.com/babylonchain/covenant-emulator/covenant"
+	"github.com/babylonchain/covenant-emulator/log"
+	"github.com/babylonchain/covenant-emulator/util"
+)
+
+var signDelegationsCommand = cli.Command{
+	Name:      "sign-delegations",
+	ShortName: "sd",
+	Usage:     "Sign BTC delegations given as newline-delimited JSON and submit the signatures to the consumer chain.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  fileFlag,
+			Usage: `The path to the file containing the delegations, or "-" to read from stdin`,
+			Value: covenant.StdinPath,
+		},
+		cli.StringFlag{
+			Name:  passphraseFlag,
+			Usage: "The pass phrase used to encrypt the keys",
+			Value: defaultPassphrase,
+		},
+		cli.StringFlag{
+			Name:  homeFlag,
+			Usage: "The path to the covenant home directory",
+			Value: covcfg.DefaultCovenantDir,
+		},
+		cli.StringFlag{
+			Name: offlineTxFileFlag,
+			Usage: "If set, the tx submitting the signatures is written unsigned to this file " +
+				"instead of being submitted, so that it can be signed and broadcast by the submitter elsewhere",
+		},
+		cli.Uint64Flag{
+			Name:  gasLimitFlag,
+			Usage: "The gas limit of the unsigned tx written to the offline tx file",
+			Value: defaultOfflineGasLimit,
+		},
+	},
+	Action: signDelegations,
+}
+
+func signDelegations(ctx *cli.Context) error {
+	homePath, err := filepath.Abs(ctx.String(homeFlag))
+	if err != nil {
+		return err
+	}
+	homePath = util.CleanAndExpandPath(homePath)
+
+	cfg, err := covcfg.LoadConfig(homePath)
+	if err != nil {
+		return fmt.Errorf("failed to load config at %s: %w", homePath, err)
+	}
+
+	logger, err := log.NewRootLoggerWithFile(covcfg.LogFile(homePath), cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		return fmt.Errorf("failed to load the logger: %w", err)
+	}
+	if cfg.LogShipAddress != "" {
+		shipper := log.NewShipper(cfg.LogShipNetwork, cfg.LogShipAddress, cfg.LogShipBufferSize)
+		defer shipper.Close()
+		logger = log.WithShipper(logger, shipper)
+	}
+	if cfg.LogSampling {
+		logger = log.WithSampling(logger)
+	}
+
+	bbnClient, err := clientcontroller.NewBabylonController(cfg.BabylonConfig, &cfg.BTCNetParams, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create rpc client for the consumer chain: %w", err)
+	}
+	defer bbnClient.Close()
+
+	ce, err := covenant.NewCovenantEmulator(cfg, bbnClient, ctx.String(passphraseFlag), logger)
+	if err != nil {
+		return fmt.Errorf("failed to create the covenant emulator: %w", err)
+	}
+
+	if offlineTxFile := ctx.String(offlineTxFileFlag); offlineTxFile != "" {
+		return buildOfflineTx(ce, ctx.String(fileFlag), offlineTxFile, ctx.Uint64(gasLimitFlag))
+	}
+
+	summary, err := ce.AddCovenantSignaturesFromFile(ctx.String(fileFlag))
+	if err != nil {
+		return fmt.Errorf("failed to sign delegations: %w", err)
+	}
+
+	printRespJSON(summary)
+
+	return nil
+}
+
+func buildOfflineTx(ce *covenant.CovenantEmulator, delsFile, txFile string, gasLimit uint64) error {
+	txJSON, err := ce.BuildCovenantSigsTxFromFile(delsFile, gasLimit)
+	if err != nil {
+		return fmt.Errorf("failed to build the covenant sigs tx: %w", err)
+	}
+	if txJSON == nil {
+		fmt.Println("none of the delegations needs to be signed")
+		return nil
+	}
+
+	if err := os.WriteFile(txFile, txJSON, 0600); err != nil {
+		return fmt.Errorf("failed to write the covenant sigs tx to %s: %w", txFile, err)
+	}
+	fmt.Printf("the unsigned covenant sigs tx is written to %s\n", txFile)
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	covcfg "github.com/babylonchain/covenant-emulator/config"
 	"github.com/babylonchain/covenant-emulator/log"
+	"github.com/babylonchain/covenant-emulator/metrics"
 	"github.com/babylonchain/covenant-emulator/util"
 
 	"github.com/lightningnetwork/lnd/signal"
@@ -31,6 +32,10 @@ var startCommand = cli.Command{
 			Usage: "The path to the covenant home directory",
 			Value: covcfg.DefaultCovenantDir,
 		},
+		cli.BoolFlag{
+			Name:  backfillFlag,
+			Usage: "Sign all the pending delegations before entering the submission loop, overriding the config",
+		},
 	},
 	Action: start,
 }
@@ -47,10 +52,22 @@ func start(ctx *cli.Context) error {
 		return fmt.Errorf("failed to load config at %s: %w", homePath, err)
 	}
 
-	logger, err := log.NewRootLoggerWithFile(covcfg.LogFile(homePath), cfg.LogLevel)
+	if ctx.Bool(backfillFlag) {
+		cfg.Backfill = true
+	}
+
+	logger, err := log.NewRootLoggerWithFile(covcfg.LogFile(homePath), cfg.LogFormat, cfg.LogLevel)
 	if err != nil {
 		return fmt.Errorf("failed to load the logger: %w", err)
 	}
+	if cfg.LogShipAddress != "" {
+		shipper := log.NewShipper(cfg.LogShipNetwork, cfg.LogShipAddress, cfg.LogShipBufferSize)
+		defer shipper.Close()
+		logger = log.WithShipper(logger, shipper)
+	}
+	if cfg.LogSampling {
+		logger = log.WithSampling(logger)
+	}
 
 	bbnClient, err := clientcontroller.NewBabylonController(cfg.BabylonConfig, &cfg.BTCNetParams, logger)
 	if err != nil {
@@ -62,6 +79,10 @@ func start(ctx *cli.Context) error {
 		return fmt.Errorf("failed to start the covenant emulator: %w", err)
 	}
 
+	if cfg.Metrics.Enabled {
+		metrics.Start(cfg.Metrics.Address(), logger)
+	}
+
 	// Hook interceptor for os signals.
 	shutdownInterceptor, err := signal.Intercept()
 	if err != nil {
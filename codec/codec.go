@@ -1,9 +1,12 @@
 package codec
 
 import (
+	btcstakingtypes "github.com/babylonchain/babylon/x/btcstaking/types"
 	"github.com/cosmos/cosmos-sdk/codec"
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	vestingtypes "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
 )
 
 func MakeCodec() *codec.ProtoCodec {
@@ -11,6 +14,9 @@ func MakeCodec() *codec.ProtoCodec {
 	cdc := codec.NewProtoCodec(ir)
 
 	cryptocodec.RegisterInterfaces(ir)
+	authtypes.RegisterInterfaces(ir)
+	vestingtypes.RegisterInterfaces(ir)
+	btcstakingtypes.RegisterInterfaces(ir)
 
 	return cdc
 }
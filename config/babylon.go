@@ -1,13 +1,18 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	bbncfg "github.com/babylonchain/rpc-client/config"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 type BBNConfig struct {
-	Key            string        `long:"key" description:"name of the key to sign transactions with"`
+	Key            string        `long:"key" description:"name of the covenant key to sign BTC transactions with"`
+	SubmitterKey   string        `long:"submitter-key" description:"name of the key of the Babylon account that submits transactions and pays fees, if different from the covenant key"`
 	ChainID        string        `long:"chain-id" description:"chain id of the chain to connect to"`
 	RPCAddr        string        `long:"rpc-address" description:"address of the rpc server to connect to"`
 	GRPCAddr       string        `long:"grpc-address" description:"address of the grpc server to connect to"`
@@ -21,8 +26,14 @@ type BBNConfig struct {
 	BlockTimeout   time.Duration `long:"block-timeout" description:"block timeout when waiting for block events"`
 	OutputFormat   string        `long:"output-format" description:"default output when printint responses"`
 	SignModeStr    string        `long:"sign-mode" description:"sign mode to use"`
+	SubmissionMemo string        `long:"submission-memo" description:"memo attached to the transactions submitting covenant signatures, e.g., the operator name or an instance id"`
+	FeeGranter     string        `long:"fee-granter" description:"bech32 address of the account that pays the fees of the submitted transactions under a fee grant to the submitter account (empty means the submitter account pays the fees)"`
 }
 
+// MaxSubmissionMemoLength is the maximum length of the submission memo, which
+// is the default memo limit of the auth module of the consumer chain
+const MaxSubmissionMemoLength = 256
+
 func DefaultBBNConfig() BBNConfig {
 	dc := bbncfg.DefaultBabylonConfig()
 	// fill up the config from dc config
@@ -45,9 +56,95 @@ func DefaultBBNConfig() BBNConfig {
 	}
 }
 
+// Validate checks the key, keyring, and fee settings used when submitting
+// transactions to Babylon. Every problem found is listed in the returned error.
+// The keyring and fee settings left unset, e.g., in config files predating
+// their validation, are set to the defaults of the Babylon client first.
+func (bc *BBNConfig) Validate() error {
+	var errs []error
+
+	bc.applyDefaults()
+
+	if bc.Key == "" {
+		errs = append(errs, fmt.Errorf("empty key name"))
+	}
+
+	if bc.ChainID == "" {
+		errs = append(errs, fmt.Errorf("empty chain id"))
+	}
+
+	switch bc.KeyringBackend {
+	case keyring.BackendOS, keyring.BackendFile, keyring.BackendKWallet,
+		keyring.BackendPass, keyring.BackendTest, keyring.BackendMemory:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported keyring backend: %s, expected one of %s, %s, %s, %s, %s, %s",
+			bc.KeyringBackend, keyring.BackendOS, keyring.BackendFile, keyring.BackendKWallet,
+			keyring.BackendPass, keyring.BackendTest, keyring.BackendMemory))
+	}
+
+	if bc.GasAdjustment <= 0 {
+		errs = append(errs, fmt.Errorf("gas adjustment must be positive, got %v", bc.GasAdjustment))
+	}
+
+	if _, err := sdk.ParseDecCoins(bc.GasPrices); err != nil {
+		errs = append(errs, fmt.Errorf("invalid gas prices %s: %w", bc.GasPrices, err))
+	}
+
+	if _, err := bc.FeeGranterAddress(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(bc.SubmissionMemo) > MaxSubmissionMemoLength {
+		errs = append(errs, fmt.Errorf("the submission memo exceeds %d characters, got %d",
+			MaxSubmissionMemoLength, len(bc.SubmissionMemo)))
+	}
+
+	return errors.Join(errs...)
+}
+
+// applyDefaults sets the keyring and fee settings left unset to the defaults
+// of the Babylon client
+func (bc *BBNConfig) applyDefaults() {
+	dc := bbncfg.DefaultBabylonConfig()
+	if bc.KeyringBackend == "" {
+		bc.KeyringBackend = dc.KeyringBackend
+	}
+	if bc.GasAdjustment == 0 {
+		bc.GasAdjustment = dc.GasAdjustment
+	}
+	if bc.GasPrices == "" {
+		bc.GasPrices = dc.GasPrices
+	}
+}
+
+// FeeGranterAddress returns the address of the fee granter, or nil if the fees
+// are paid by the submitter account
+func (bc *BBNConfig) FeeGranterAddress() (sdk.AccAddress, error) {
+	if bc.FeeGranter == "" {
+		return nil, nil
+	}
+
+	granter, err := sdk.GetFromBech32(bc.FeeGranter, bc.AccountPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fee granter %s: %w", bc.FeeGranter, err)
+	}
+
+	return granter, nil
+}
+
+// TxSignerKey returns the name of the key that signs the Babylon transactions,
+// which is the submitter key if set and the covenant key otherwise
+func (bc *BBNConfig) TxSignerKey() string {
+	if bc.SubmitterKey != "" {
+		return bc.SubmitterKey
+	}
+
+	return bc.Key
+}
+
 func BBNConfigToBabylonConfig(bc *BBNConfig) bbncfg.BabylonConfig {
 	return bbncfg.BabylonConfig{
-		Key:              bc.Key,
+		Key:              bc.TxSignerKey(),
 		ChainID:          bc.ChainID,
 		RPCAddr:          bc.RPCAddr,
 		AccountPrefix:    bc.AccountPrefix,
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"path/filepath"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 
 const (
 	defaultLogLevel        = "debug"
+	defaultLogFormat       = "console"
 	defaultLogFilename     = "covd.log"
 	defaultConfigFileName  = "covd.conf"
 	defaultCovenantKeyName = "covenant-key"
@@ -22,6 +25,60 @@ const (
 	defaultSigsBatchSize   = uint64(20)
 	defaultBitcoinNetwork  = "simnet"
 	defaultLogDirname      = "logs"
+
+	defaultMaxSigsBeforeYield       = uint32(16)
+	defaultBreakerCooldown          = time.Minute
+	defaultKeyringInitAttempts      = uint32(3)
+	defaultKeyringInitRetryDelay    = time.Second
+	defaultParamsChangeLogInterval  = time.Minute
+	defaultConfirmationTimeout      = time.Minute
+	defaultConfirmationMaxAttempts  = uint32(10)
+	defaultEmptyTickSummaryInterval = 10 * time.Minute
+	defaultResultsDatabaseDriver    = "sqlite3"
+	defaultLogShipNetwork           = "tcp"
+	defaultLogShipBufferSize        = 10000
+	defaultPendingQueryAttempts     = uint32(3)
+	defaultPendingQueryRetryDelay   = time.Second
+	defaultPendingQueryTimeout      = 30 * time.Second
+	defaultStuckSubmissionMaxAge    = time.Hour
+
+	// DelegationOrderNone keeps the delegations in the order returned by the consumer chain
+	DelegationOrderNone = "none"
+	// DelegationOrderValueDesc signs the delegations with the largest stake first
+	DelegationOrderValueDesc = "value-desc"
+	// DelegationOrderOldestFirst signs the delegations with the lowest start height first
+	DelegationOrderOldestFirst = "oldest-first"
+
+	// SigHashTypeDefault is the taproot SIGHASH_DEFAULT which commits to the
+	// whole tx, the only sighash type that Babylon accepts for covenant sigs
+	SigHashTypeDefault = "default"
+
+	// SubmissionConfirmationNone submits the sigs without waiting for them
+	// to be included in the delegations
+	SubmissionConfirmationNone = "none"
+	// SubmissionConfirmationBestEffort waits for the submitted sigs to be
+	// included and logs those which are not
+	SubmissionConfirmationBestEffort = "best-effort"
+	// SubmissionConfirmationRequired waits for the submitted sigs to be
+	// included and retries the delegations of which they are not
+	SubmissionConfirmationRequired = "required"
+
+	// SupersededCommitteeSkip skips the delegations staked under a superseded
+	// covenant committee for good
+	SupersededCommitteeSkip = "skip"
+	// SupersededCommitteeDefer skips the delegations staked under a superseded
+	// covenant committee in the current round only, in case the staking params
+	// are stale during a committee change
+	SupersededCommitteeDefer = "defer"
+
+	// EmptyTickLogEvery logs every round without pending delegations
+	EmptyTickLogEvery = "every"
+	// EmptyTickLogTransition logs only the first round without pending
+	// delegations after a round with some
+	EmptyTickLogTransition = "transition"
+	// EmptyTickLogSummary logs the first round without pending delegations
+	// after a round with some, then summarizes the empty rounds periodically
+	EmptyTickLogSummary = "summary"
 )
 
 var (
@@ -35,15 +92,76 @@ var (
 )
 
 type Config struct {
-	LogLevel        string        `long:"loglevel" description:"Logging level for all subsystems" choice:"trace" choice:"debug" choice:"info" choice:"warn" choice:"error" choice:"fatal"`
-	QueryInterval   time.Duration `long:"queryinterval" description:"The interval between each query for pending BTC delegations"`
-	DelegationLimit uint64        `long:"delegationlimit" description:"The maximum number of delegations that the Covenant processes each time"`
-	SigsBatchSize   uint64        `long:"sigsbatchsize" description:"The maximum number of signatures to send in a single transaction"`
-	BitcoinNetwork  string        `long:"bitcoinnetwork" description:"Bitcoin network to run on" choice:"mainnet" choice:"regtest" choice:"testnet" choice:"simnet" choice:"signet"`
+	LogLevel                   string        `long:"loglevel" description:"Logging level for all subsystems" choice:"trace" choice:"debug" choice:"info" choice:"warn" choice:"error" choice:"fatal"`
+	LogFormat                  string        `long:"logformat" description:"Format of the logs" choice:"console" choice:"json" choice:"logfmt"`
+	LogSampling                bool          `long:"logsampling" description:"Whether to sample repeated log entries to limit the log volume"`
+	LogShipAddress             string        `long:"logshipaddress" description:"The address of a remote collector, e.g., a syslog server or a Loki agent, to which the logs are also shipped as newline-delimited JSON (empty means disabled)"`
+	LogShipNetwork             string        `long:"logshipnetwork" description:"The network of the remote log collector" choice:"tcp" choice:"udp"`
+	LogShipBufferSize          int           `long:"logshipbuffersize" description:"The maximum number of log entries buffered for the remote collector, beyond which entries are dropped"`
+	QueryInterval              time.Duration `long:"queryinterval" description:"The interval between each query for pending BTC delegations"`
+	DelegationLimit            uint64        `long:"delegationlimit" description:"The maximum number of delegations that the Covenant processes each time"`
+	PendingQueryAttempts       uint32        `long:"pendingqueryattempts" description:"The number of attempts to query the pending delegations in a round before skipping it (0 or 1 means no retry)"`
+	PendingQueryRetryDelay     time.Duration `long:"pendingqueryretrydelay" description:"The delay between the attempts to query the pending delegations in a round"`
+	PendingQueryTimeout        time.Duration `long:"pendingquerytimeout" description:"The maximum time of each attempt to query the pending delegations (0 means no timeout)"`
+	Backfill                   bool          `long:"backfill" description:"Whether to sign all the pending delegations page by page on start before entering the submission loop, which is meant for catching up on the backlog with a new covenant key"`
+	PaginateDelegations        bool          `long:"paginatedelegations" description:"Whether each round queries the next page of pending delegations of at most the delegation limit, instead of always the first page"`
+	DelegationCursorFile       string        `long:"delegationcursorfile" description:"The file to persist the page of pending delegations to query next, so that paging resumes after a restart (empty means not persisted)"`
+	SigsBatchSize              uint64        `long:"sigsbatchsize" description:"The maximum number of signatures to send in a single transaction"`
+	MaxConcurrentSigning       uint32        `long:"maxconcurrentsigning" description:"The maximum number of delegations of a batch that are signed concurrently, which is CPU-bound (0 or 1 means sequentially)"`
+	MaxConcurrentSubmissions   uint32        `long:"maxconcurrentsubmissions" description:"The maximum number of batches of a round that are signed, submitted and confirmed concurrently; the txs are still broadcast one at a time as they share the sequence of the submitter account (0 or 1 means sequentially)"`
+	BitcoinNetwork             string        `long:"bitcoinnetwork" description:"Bitcoin network to run on" choice:"mainnet" choice:"regtest" choice:"testnet" choice:"simnet" choice:"signet"`
+	DetailedValidation         bool          `long:"detailedvalidation" description:"Whether to log the slashing amount breakdown of each delegation at debug level before signing"`
+	ConsensusChecks            bool          `long:"consensuschecks" description:"Whether to also check the txs of each delegation against the context-free BTC consensus and standardness rules before signing, on top of the checks of the staking protocol"`
+	LogDecodedDelegations      bool          `long:"logdecodeddelegations" description:"Whether to log every delegation with its decoded txs at debug level before signing it, which is verbose and meant for debugging"`
+	MaxKeyringUnlockFailures   uint32        `long:"maxkeyringunlockfailures" description:"The number of consecutive failures to unlock the covenant key after which the signing loop halts (0 means never halt)"`
+	KeyringInitAttempts        uint32        `long:"keyringinitattempts" description:"The number of attempts to open the keyring and load the covenant key on start, which helps with keyring backends that are not instantly available (0 or 1 means no retry)"`
+	KeyringInitRetryDelay      time.Duration `long:"keyringinitretrydelay" description:"The delay between the attempts to open the keyring on start"`
+	MinStakingConfirmations    uint64        `long:"minstakingconfirmations" description:"The minimum number of BTC confirmations of the staking tx required before signing a delegation (0 means no requirement)"`
+	MinStakingTime             uint16        `long:"minstakingtime" description:"The minimum staking time in BTC blocks of delegations to sign (0 means no lower bound)"`
+	MaxStakingTime             uint16        `long:"maxstakingtime" description:"The maximum staking time in BTC blocks of delegations to sign (0 means no upper bound)"`
+	MaxDelegationsPerFpPerTick uint64        `long:"maxdelegationsperfppertick" description:"The maximum number of delegations to the same finality provider that are signed in a round, the rest are deferred to later rounds (0 means no limit)"`
+	MaxParamsAge               time.Duration `long:"maxparamsage" description:"The maximum age of the last known staking params that are used when querying the params fails (0 means stop signing until the params are queried)"`
+	ParamsChangeLogInterval    time.Duration `long:"paramschangeloginterval" description:"The minimum interval between the logs of staking params changes, changes within it are summarized in the next log (0 means every change is logged)"`
+	EmptyTickLog               string        `long:"emptyticklog" description:"When the rounds without pending delegations are logged at debug level, every round, only the first after a round with delegations, or the first and then periodic summaries" choice:"every" choice:"transition" choice:"summary"`
+	EmptyTickSummaryInterval   time.Duration `long:"emptyticksummaryinterval" description:"The interval between the summaries of the rounds without pending delegations if emptyticklog is summary"`
+	ExpiryWarningBlocks        uint64        `long:"expirywarningblocks" description:"The number of BTC blocks before the staking timelock of a pending delegation expires within which it is prioritized and an alert is logged (0 means no alert)"`
+	ProcessingWindows          []string      `long:"processingwindows" description:"A daily window in UTC formatted as HH:MM-HH:MM, which may wrap midnight, during which pending delegations are signed and submitted, can be specified multiple times; the params and pending delegations are still queried outside the windows (empty means always)"`
+	OffWindowExpiryBlocks      uint64        `long:"offwindowexpiryblocks" description:"The number of BTC blocks before the staking timelock of a pending delegation expires within which it is signed and submitted outside the processing windows (0 means never)"`
+	DetectStakingReorgs        bool          `long:"detectstakingreorgs" description:"Whether to check every round that the staking txs of the pending delegations are still in the BTC chain, deferring and logging those reorged out"`
+	ReconcileInterval          time.Duration `long:"reconcileinterval" description:"The interval of re-verifying the submitted covenant signatures on pending delegations under the current staking params (0 means never)"`
+	MinRetryInterval           time.Duration `long:"minretryinterval" description:"The initial interval before retrying a delegation that failed to be signed or submitted, doubled upon each failure with the same cause (0 means retry every round)"`
+	MaxRetryInterval           time.Duration `long:"maxretryinterval" description:"The maximum interval before retrying a delegation that failed to be signed or submitted, which must be set along with the min"`
+	BreakerThreshold           uint32        `long:"breakerthreshold" description:"The number of consecutive failures to query or submit to the consumer chain after which the emulator pauses for a cooldown (0 means never pause)"`
+	BreakerCooldown            time.Duration `long:"breakercooldown" description:"The period during which the emulator pauses querying and submitting to the consumer chain after repeated failures"`
+	DelegationOrder            string        `long:"delegationorder" description:"The order in which pending delegations are signed within a round" choice:"none" choice:"value-desc" choice:"oldest-first"`
+	SigHashType                string        `long:"sighashtype" description:"The sighash type of the covenant signatures, which must be the one expected by the consumer chain" choice:"default"`
+	SubmissionConfirmation     string        `long:"submissionconfirmation" description:"Whether the submission loop waits for the submitted sigs to be included in the delegations, and whether the delegations are retried if not" choice:"none" choice:"best-effort" choice:"required"`
+	StuckSubmissionTimeout     time.Duration `long:"stucksubmissiontimeout" description:"The time after which submitted covenant signatures that are not included in the pending delegation are reported as stuck, before which the delegation is not submitted again (0 means not tracked)"`
+	StuckSubmissionMaxAge      time.Duration `long:"stucksubmissionmaxage" description:"The time after which the delegation of a stuck submission is submitted again, by when the stuck tx is expected to be evicted from the mempool (0 means deferred for as long as it is pending)"`
+	ConfirmationTimeout        time.Duration `long:"confirmationtimeout" description:"The maximum time of waiting for the submitted sigs of a delegation to be included"`
+	ConfirmationMaxAttempts    uint32        `long:"confirmationmaxattempts" description:"The maximum number of polls with exponential backoff for the submitted sigs of a delegation to be included (0 means polling until the timeout)"`
+	MaxSlashingTxFeeSat        uint64        `long:"maxslashingtxfeesat" description:"The maximum fee in satoshis of the slashing txs of delegations to sign, on top of the minimum fee in the staking params (0 means no upper bound)"`
+	AllowedSlashingAddresses   []string      `long:"allowedslashingaddresses" description:"The slashing addresses that delegations are allowed to be signed against, can be specified multiple times (empty means any address in the staking params)"`
+	OnlyDecidingSig            bool          `long:"onlydecidingsig" description:"Whether to only sign delegations that our signature brings to the covenant quorum, deferring the others to the rest of the committee to save gas; delegations may never be activated if other members do not sign"`
+	SupersededCommittee        string        `long:"supersededcommittee" description:"Whether the delegations staked under a covenant committee other than the current one are skipped for good or retried in later rounds" choice:"skip" choice:"defer"`
+	Standby                    bool          `long:"standby" description:"Whether to start as a standby that computes but does not submit covenant signatures until promoted"`
+	MaxClientFailures          uint32        `long:"maxclientfailures" description:"The number of consecutive failures to query or submit to the consumer chain after which the client reconnects (0 means never reconnect)"`
+	CreateKeyIfMissing         bool          `long:"createkeyifmissing" description:"Whether to create the covenant key on start if it is not in the keyring, the new key must be registered in the covenant committee before it can sign (only for automated provisioning)"`
+	RequireParamsOnStart       bool          `long:"requireparamsonstart" description:"Whether to fail to start if the staking params cannot be queried, instead of querying them in the first round"`
+	MaxSigsBeforeYield         uint32        `long:"maxsigsbeforeyield" description:"The number of adaptor signatures computed for a delegation before checking for shutdown, which bounds the shutdown latency for delegations to many finality providers (0 means never check)"`
+	PoolTxBuffers              bool          `long:"pooltxbuffers" description:"Whether to reuse the buffers of decoding txs across delegations, which reduces allocations when signing large batches"`
+	PprofAddress               string        `long:"pprofaddress" description:"The address to serve the pprof profiling endpoints at, which should not be publicly reachable (empty means disabled)"`
+	DecisionTraceFile          string        `long:"decisiontracefile" description:"The file to append the trace of every check and signing step of each processed delegation to as newline-delimited JSON, which is meant for auditing (empty means disabled)"`
+	ResultsDatabase            string        `long:"resultsdatabase" description:"The data source name of the SQL database, e.g., the path of a SQLite file, to record the result of every processed delegation to for local reporting (empty means disabled)"`
+	ResultsDatabaseDriver      string        `long:"resultsdatabasedriver" description:"The database/sql driver of the results database, which must be linked into the binary"`
 
 	BTCNetParams chaincfg.Params
 
 	BabylonConfig *BBNConfig `group:"babylon" namespace:"babylon"`
+
+	Metrics *MetricsConfig `group:"metrics" namespace:"metrics"`
+
+	Heartbeat *HeartbeatConfig `group:"heartbeat" namespace:"heartbeat"`
 }
 
 // LoadConfig initializes and parses the config using a config file and command
@@ -81,8 +199,12 @@ func LoadConfig(homePath string) (*Config, error) {
 
 // Validate check the given configuration to be sane. This makes sure no
 // illegal values or combination of values are set. All file system paths are
-// normalized. The cleaned up config is returned on success.
+// normalized. Every problem found is listed in the returned error rather than
+// only the first one.
 func (cfg *Config) Validate() error {
+	var errs []error
+
+	validNetwork := true
 	switch cfg.BitcoinNetwork {
 	case "mainnet":
 		cfg.BTCNetParams = chaincfg.MainNetParams
@@ -95,10 +217,198 @@ func (cfg *Config) Validate() error {
 	case "signet":
 		cfg.BTCNetParams = chaincfg.SigNetParams
 	default:
-		return fmt.Errorf("unsupported Bitcoin network: %s", cfg.BitcoinNetwork)
+		validNetwork = false
+		errs = append(errs, fmt.Errorf("unsupported Bitcoin network: %s, "+
+			"expected one of mainnet, testnet, regtest, simnet, signet", cfg.BitcoinNetwork))
+	}
+
+	if cfg.QueryInterval <= 0 {
+		errs = append(errs, fmt.Errorf("query interval must be positive, got %v", cfg.QueryInterval))
+	}
+
+	if cfg.SigsBatchSize == 0 {
+		errs = append(errs, fmt.Errorf("sigs batch size must be positive"))
+	}
+
+	if cfg.MinRetryInterval < 0 || cfg.MaxRetryInterval < cfg.MinRetryInterval {
+		errs = append(errs, fmt.Errorf("invalid retry intervals: min %v, max %v, "+
+			"the min must not be negative or larger than the max", cfg.MinRetryInterval, cfg.MaxRetryInterval))
+	}
+
+	if cfg.BreakerThreshold != 0 && cfg.BreakerCooldown <= 0 {
+		errs = append(errs, fmt.Errorf("breaker cooldown must be positive if the breaker threshold is set, got %v",
+			cfg.BreakerCooldown))
+	}
+
+	if cfg.ReconcileInterval < 0 {
+		errs = append(errs, fmt.Errorf("reconcile interval must not be negative, got %v", cfg.ReconcileInterval))
+	}
+
+	if cfg.PendingQueryRetryDelay < 0 {
+		errs = append(errs, fmt.Errorf("pending query retry delay must not be negative, got %v", cfg.PendingQueryRetryDelay))
+	}
+
+	if cfg.PendingQueryTimeout < 0 {
+		errs = append(errs, fmt.Errorf("pending query timeout must not be negative, got %v", cfg.PendingQueryTimeout))
+	}
+
+	if cfg.KeyringInitRetryDelay < 0 {
+		errs = append(errs, fmt.Errorf("keyring init retry delay must not be negative, got %v", cfg.KeyringInitRetryDelay))
+	}
+
+	if cfg.ParamsChangeLogInterval < 0 {
+		errs = append(errs, fmt.Errorf("params change log interval must not be negative, got %v",
+			cfg.ParamsChangeLogInterval))
+	}
+
+	if cfg.StuckSubmissionMaxAge < 0 {
+		errs = append(errs, fmt.Errorf("stuck submission max age must not be negative, got %v", cfg.StuckSubmissionMaxAge))
+	}
+
+	if cfg.MaxParamsAge < 0 {
+		errs = append(errs, fmt.Errorf("max params age must not be negative, got %v", cfg.MaxParamsAge))
+	}
+
+	switch cfg.LogFormat {
+	case "":
+		cfg.LogFormat = defaultLogFormat
+	case "console", "json", "logfmt":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported log format: %s, expected one of console, json, logfmt",
+			cfg.LogFormat))
+	}
+
+	switch cfg.DelegationOrder {
+	case "":
+		cfg.DelegationOrder = DelegationOrderNone
+	case DelegationOrderNone, DelegationOrderValueDesc, DelegationOrderOldestFirst:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported delegation order: %s, expected one of %s, %s, %s",
+			cfg.DelegationOrder, DelegationOrderNone, DelegationOrderValueDesc, DelegationOrderOldestFirst))
+	}
+
+	if cfg.PprofAddress != "" {
+		if _, _, err := net.SplitHostPort(cfg.PprofAddress); err != nil {
+			errs = append(errs, fmt.Errorf("invalid pprof address %s: %w", cfg.PprofAddress, err))
+		}
+	}
+
+	switch cfg.SigHashType {
+	case "":
+		cfg.SigHashType = SigHashTypeDefault
+	case SigHashTypeDefault:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported sighash type: %s, the consumer chain expects %s",
+			cfg.SigHashType, SigHashTypeDefault))
+	}
+
+	switch cfg.SubmissionConfirmation {
+	case "":
+		cfg.SubmissionConfirmation = SubmissionConfirmationNone
+	case SubmissionConfirmationNone, SubmissionConfirmationBestEffort, SubmissionConfirmationRequired:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported submission confirmation: %s, expected one of %s, %s, %s",
+			cfg.SubmissionConfirmation, SubmissionConfirmationNone, SubmissionConfirmationBestEffort,
+			SubmissionConfirmationRequired))
+	}
+
+	if cfg.ResultsDatabase != "" && cfg.ResultsDatabaseDriver == "" {
+		errs = append(errs, fmt.Errorf("the results database driver must be set if the results database is"))
+	}
+
+	switch cfg.SupersededCommittee {
+	case "":
+		cfg.SupersededCommittee = SupersededCommitteeSkip
+	case SupersededCommitteeSkip, SupersededCommitteeDefer:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported superseded committee behavior: %s, expected one of %s, %s",
+			cfg.SupersededCommittee, SupersededCommitteeSkip, SupersededCommitteeDefer))
+	}
+
+	if cfg.LogShipAddress != "" {
+		switch cfg.LogShipNetwork {
+		case "":
+			cfg.LogShipNetwork = defaultLogShipNetwork
+		case "tcp", "udp":
+		default:
+			errs = append(errs, fmt.Errorf("unsupported log ship network: %s, expected one of tcp, udp", cfg.LogShipNetwork))
+		}
+		if cfg.LogShipBufferSize <= 0 {
+			errs = append(errs, fmt.Errorf("log ship buffer size must be positive, got %d", cfg.LogShipBufferSize))
+		}
+	}
+
+	switch cfg.EmptyTickLog {
+	case "":
+		cfg.EmptyTickLog = EmptyTickLogEvery
+	case EmptyTickLogEvery, EmptyTickLogTransition, EmptyTickLogSummary:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported empty tick log: %s, expected one of %s, %s, %s",
+			cfg.EmptyTickLog, EmptyTickLogEvery, EmptyTickLogTransition, EmptyTickLogSummary))
+	}
+
+	if cfg.EmptyTickLog == EmptyTickLogSummary && cfg.EmptyTickSummaryInterval <= 0 {
+		errs = append(errs, fmt.Errorf("empty tick summary interval must be positive if empty ticks are summarized, got %v",
+			cfg.EmptyTickSummaryInterval))
+	}
+
+	for _, w := range cfg.ProcessingWindows {
+		if _, err := ParseProcessingWindow(w); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if cfg.SubmissionConfirmation != SubmissionConfirmationNone && cfg.ConfirmationTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("confirmation timeout must be positive if the submission is confirmed, got %v",
+			cfg.ConfirmationTimeout))
+	}
+
+	if cfg.MaxStakingTime != 0 && cfg.MinStakingTime > cfg.MaxStakingTime {
+		errs = append(errs, fmt.Errorf("min staking time %d must not be larger than max staking time %d",
+			cfg.MinStakingTime, cfg.MaxStakingTime))
+	}
+
+	// normalize the allowed slashing addresses for comparison, which
+	// can only be decoded once the network is known
+	if validNetwork {
+		for i, addr := range cfg.AllowedSlashingAddresses {
+			decoded, err := btcutil.DecodeAddress(addr, &cfg.BTCNetParams)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid allowed slashing address %s for network %s: %w",
+					addr, cfg.BitcoinNetwork, err))
+				continue
+			}
+			cfg.AllowedSlashingAddresses[i] = decoded.EncodeAddress()
+		}
+	}
+
+	if cfg.BabylonConfig == nil {
+		errs = append(errs, fmt.Errorf("empty babylon config"))
+	} else if err := cfg.BabylonConfig.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid babylon config: %w", err))
+	}
+
+	// the metrics group is missing in config files predating it, in which
+	// case the metrics server is disabled
+	if cfg.Metrics == nil {
+		metricsCfg := DefaultMetricsConfig()
+		cfg.Metrics = &metricsCfg
+	} else {
+		cfg.Metrics.applyDefaults()
+		if err := cfg.Metrics.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid metrics config: %w", err))
+		}
+	}
+
+	// the heartbeat is optional and disabled if missing in the config file
+	if cfg.Heartbeat == nil {
+		heartbeatCfg := DefaultHeartbeatConfig()
+		cfg.Heartbeat = &heartbeatCfg
+	} else if err := cfg.Heartbeat.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid heartbeat config: %w", err))
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 func ConfigFile(homePath string) string {
@@ -117,14 +427,39 @@ func DefaultConfigWithHomePath(homePath string) Config {
 	bbnCfg := DefaultBBNConfig()
 	bbnCfg.Key = defaultCovenantKeyName
 	bbnCfg.KeyDirectory = homePath
+	metricsCfg := DefaultMetricsConfig()
+	heartbeatCfg := DefaultHeartbeatConfig()
 	cfg := Config{
-		LogLevel:        defaultLogLevel,
-		QueryInterval:   defaultQueryInterval,
-		DelegationLimit: defaultDelegationLimit,
-		SigsBatchSize:   defaultSigsBatchSize,
-		BitcoinNetwork:  defaultBitcoinNetwork,
-		BTCNetParams:    defaultBTCNetParams,
-		BabylonConfig:   &bbnCfg,
+		LogLevel:                 defaultLogLevel,
+		LogFormat:                defaultLogFormat,
+		QueryInterval:            defaultQueryInterval,
+		DelegationLimit:          defaultDelegationLimit,
+		SigsBatchSize:            defaultSigsBatchSize,
+		BitcoinNetwork:           defaultBitcoinNetwork,
+		BTCNetParams:             defaultBTCNetParams,
+		BabylonConfig:            &bbnCfg,
+		Metrics:                  &metricsCfg,
+		Heartbeat:                &heartbeatCfg,
+		MaxSigsBeforeYield:       defaultMaxSigsBeforeYield,
+		DelegationOrder:          DelegationOrderNone,
+		SigHashType:              SigHashTypeDefault,
+		SubmissionConfirmation:   SubmissionConfirmationNone,
+		ConfirmationTimeout:      defaultConfirmationTimeout,
+		ConfirmationMaxAttempts:  defaultConfirmationMaxAttempts,
+		LogShipNetwork:           defaultLogShipNetwork,
+		LogShipBufferSize:        defaultLogShipBufferSize,
+		EmptyTickLog:             EmptyTickLogEvery,
+		SupersededCommittee:      SupersededCommitteeSkip,
+		EmptyTickSummaryInterval: defaultEmptyTickSummaryInterval,
+		ResultsDatabaseDriver:    defaultResultsDatabaseDriver,
+		BreakerCooldown:          defaultBreakerCooldown,
+		KeyringInitAttempts:      defaultKeyringInitAttempts,
+		KeyringInitRetryDelay:    defaultKeyringInitRetryDelay,
+		PendingQueryAttempts:     defaultPendingQueryAttempts,
+		PendingQueryRetryDelay:   defaultPendingQueryRetryDelay,
+		PendingQueryTimeout:      defaultPendingQueryTimeout,
+		ParamsChangeLogInterval:  defaultParamsChangeLogInterval,
+		StuckSubmissionMaxAge:    defaultStuckSubmissionMaxAge,
 	}
 
 	if err := cfg.Validate(); err != nil {
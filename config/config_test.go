@@ -0,0 +1,100 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/covenant-emulator/config"
+)
+
+// TestMetricsConfigDefaults checks that a missing or empty metrics group
+// falls back to the default metrics server while explicitly set invalid
+// values are still rejected
+func TestMetricsConfigDefaults(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Metrics = nil
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, config.DefaultMetricsConfig(), *cfg.Metrics)
+
+	cfg.Metrics = &config.MetricsConfig{}
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, config.DefaultMetricsConfig(), *cfg.Metrics)
+
+	cfg.Metrics = &config.MetricsConfig{Host: "not-an-ip"}
+	require.Error(t, cfg.Validate())
+
+	cfg.Metrics = &config.MetricsConfig{Port: 70000}
+	require.Error(t, cfg.Validate())
+}
+
+// preSeriesConfigFile is a covd.conf as written by earlier releases, without
+// the groups and options added since and with the keyring and fee settings
+// left unset
+const preSeriesConfigFile = `[Application Options]
+LogLevel = info
+QueryInterval = 15s
+DelegationLimit = 100
+SigsBatchSize = 20
+BitcoinNetwork = simnet
+
+[babylon]
+Key = covenant-key
+ChainID = chain-test
+RPCAddr = http://127.0.0.1:26657
+GRPCAddr = https://127.0.0.1:9090
+AccountPrefix = bbn
+Timeout = 20s
+BlockTimeout = 1m0s
+OutputFormat = json
+SignModeStr = direct
+`
+
+// TestLoadPreSeriesConfig checks that a config file written by earlier
+// releases is still loaded, with the unset options set to their defaults
+func TestLoadPreSeriesConfig(t *testing.T) {
+	homePath := t.TempDir()
+	err := os.WriteFile(filepath.Join(homePath, "covd.conf"), []byte(preSeriesConfigFile), 0600)
+	require.NoError(t, err)
+
+	cfg, err := config.LoadConfig(homePath)
+	require.NoError(t, err)
+
+	defaultBBNCfg := config.DefaultBBNConfig()
+	require.Equal(t, defaultBBNCfg.KeyringBackend, cfg.BabylonConfig.KeyringBackend)
+	require.Equal(t, defaultBBNCfg.GasAdjustment, cfg.BabylonConfig.GasAdjustment)
+	require.Equal(t, defaultBBNCfg.GasPrices, cfg.BabylonConfig.GasPrices)
+	require.Equal(t, config.DefaultMetricsConfig(), *cfg.Metrics)
+	require.Empty(t, cfg.Heartbeat.URL)
+
+	// the behaviors added since are opt-in, so an upgraded deployment
+	// neither serves metrics nor halts, reconnects, pauses or backs off
+	require.False(t, cfg.Metrics.Enabled)
+	require.Zero(t, cfg.MaxKeyringUnlockFailures)
+	require.Zero(t, cfg.MaxClientFailures)
+	require.Zero(t, cfg.BreakerThreshold)
+	require.Zero(t, cfg.MinRetryInterval)
+}
+
+// TestFeeGranterValidation checks that the fee granter must be a bech32
+// address with the account prefix
+func TestFeeGranterValidation(t *testing.T) {
+	cfg := config.DefaultBBNConfig()
+	require.NoError(t, cfg.Validate())
+
+	cfg.FeeGranter = "not-an-address"
+	require.Error(t, cfg.Validate())
+
+	granter := []byte("fee-granter-account")
+	cfg.FeeGranter = sdk.MustBech32ifyAddressBytes("cosmos", granter)
+	require.Error(t, cfg.Validate())
+
+	cfg.FeeGranter = sdk.MustBech32ifyAddressBytes(cfg.AccountPrefix, granter)
+	require.NoError(t, cfg.Validate())
+	addr, err := cfg.FeeGranterAddress()
+	require.NoError(t, err)
+	require.Equal(t, sdk.AccAddress(granter), addr)
+}
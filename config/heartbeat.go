@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	defaultHeartbeatInterval = time.Minute
+	defaultHeartbeatTimeout  = 10 * time.Second
+)
+
+// HeartbeatConfig defines the heartbeat sent to an external monitor
+// upon successful rounds of the submission loop
+type HeartbeatConfig struct {
+	URL      string        `long:"url" description:"The URL that is pinged upon successful rounds of the submission loop, e.g., of a dead man's switch monitor (empty means no heartbeat)"`
+	Interval time.Duration `long:"interval" description:"The minimum interval between two heartbeats"`
+	Timeout  time.Duration `long:"timeout" description:"The timeout of a heartbeat request"`
+}
+
+func (cfg *HeartbeatConfig) Validate() error {
+	if cfg.URL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url %s: %w", cfg.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid url %s: the scheme must be http or https", cfg.URL)
+	}
+
+	if cfg.Interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %v", cfg.Interval)
+	}
+
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got %v", cfg.Timeout)
+	}
+
+	return nil
+}
+
+func DefaultHeartbeatConfig() HeartbeatConfig {
+	return HeartbeatConfig{
+		Interval: defaultHeartbeatInterval,
+		Timeout:  defaultHeartbeatTimeout,
+	}
+}
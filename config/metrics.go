@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+const (
+	defaultMetricsHost = "127.0.0.1"
+	defaultMetricsPort = 2112
+)
+
+// MetricsConfig defines the server's basic configuration
+type MetricsConfig struct {
+	Enabled bool   `long:"enabled" description:"Whether the Prometheus server is started"`
+	Host    string `long:"host" description:"IP of the Prometheus server"`
+	Port    int    `long:"port" description:"Port of the Prometheus server"`
+}
+
+func (cfg *MetricsConfig) Validate() error {
+	if cfg.Port < 0 || cfg.Port > 65535 {
+		return fmt.Errorf("invalid port: %d", cfg.Port)
+	}
+
+	ip := net.ParseIP(cfg.Host)
+	if ip == nil {
+		return fmt.Errorf("invalid host: %v", cfg.Host)
+	}
+
+	return nil
+}
+
+// applyDefaults sets the fields left unset in the config file to their
+// default values
+func (cfg *MetricsConfig) applyDefaults() {
+	if cfg.Host == "" {
+		cfg.Host = defaultMetricsHost
+	}
+	if cfg.Port == 0 {
+		cfg.Port = defaultMetricsPort
+	}
+}
+
+func (cfg *MetricsConfig) Address() string {
+	return net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+}
+
+func DefaultMetricsConfig() MetricsConfig {
+	return MetricsConfig{
+		Host: defaultMetricsHost,
+		Port: defaultMetricsPort,
+	}
+}
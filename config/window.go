@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProcessingWindow is a daily window in UTC given by its start and end as
+// offsets from midnight. A window of which the end is before the start wraps
+// midnight.
+type ProcessingWindow struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseProcessingWindow parses a window formatted as HH:MM-HH:MM
+func ParseProcessingWindow(s string) (ProcessingWindow, error) {
+	startStr, endStr, ok := strings.Cut(s, "-")
+	if !ok {
+		return ProcessingWindow{}, fmt.Errorf("invalid processing window %s, expected HH:MM-HH:MM", s)
+	}
+
+	start, err := time.Parse("15:04", strings.TrimSpace(startStr))
+	if err != nil {
+		return ProcessingWindow{}, fmt.Errorf("invalid start of processing window %s: %w", s, err)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(endStr))
+	if err != nil {
+		return ProcessingWindow{}, fmt.Errorf("invalid end of processing window %s: %w", s, err)
+	}
+	if start.Equal(end) {
+		return ProcessingWindow{}, fmt.Errorf("empty processing window %s", s)
+	}
+
+	midnight := time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)
+	return ProcessingWindow{
+		Start: start.Sub(midnight),
+		End:   end.Sub(midnight),
+	}, nil
+}
+
+// Contains returns whether the given time is within the window
+func (w ProcessingWindow) Contains(t time.Time) bool {
+	t = t.UTC()
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+
+	return offset >= w.Start || offset < w.End
+}
@@ -0,0 +1,82 @@
+package covenant
+
+import (
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// backfill signs all the currently pending delegations page by page before
+// the submission loop starts, without waiting for a round per page, so that a
+// new covenant key catches up on the backlog of a busy chain. Delegations
+// that fail or are deferred are left to the submission loop.
+func (ce *CovenantEmulator) backfill() {
+	if ce.standby.Load() {
+		ce.logger.Info("skipping the backfill of pending delegations as the emulator is standby")
+		return
+	}
+
+	if err := ce.UpdateParams(); err != nil {
+		ce.logger.Warn("failed to get staking params for the backfill, "+
+			"leaving the pending delegations to the submission loop", zap.Error(err))
+		return
+	}
+
+	ctx, cancel := ce.quitContext()
+	defer cancel()
+
+	start := time.Now()
+	limit := ce.config.DelegationLimit
+	var (
+		pageKey           []byte
+		numPages, numDels int
+	)
+	for {
+		select {
+		case <-ce.quit:
+			return
+		default:
+		}
+
+		dels, nextKey, err := ce.cc.QueryPendingDelegationsPage(ctx, limit, pageKey)
+		if err != nil {
+			ce.logger.Warn("failed to query pending delegations for the backfill, "+
+				"leaving the rest to the submission loop", zap.Error(err))
+			return
+		}
+		numPages++
+
+		// the delegations are checked as in the submission loop
+		dels, err = ce.sanitizeDelegations(dels)
+		if err == nil {
+			dels, err = ce.removeOutsideWindow(dels)
+		}
+		if err != nil {
+			ce.logger.Warn("failed to sanitize pending delegations for the backfill, "+
+				"leaving the rest to the submission loop", zap.Error(err))
+			return
+		}
+		numDels += len(dels)
+		for _, delBatch := range ce.delegationsToBatches(dels) {
+			if _, err := ce.AddCovenantSignatures(delBatch); err != nil {
+				if errors.Is(err, ErrShuttingDown) {
+					return
+				}
+				ce.logger.Debug("failed to submit covenant signatures in the backfill", zap.Error(err))
+			}
+		}
+
+		if len(nextKey) == 0 {
+			break
+		}
+		pageKey = nextKey
+	}
+
+	ce.logger.Info("finished the backfill of pending delegations",
+		zap.Int("pages", numPages),
+		zap.Int("delegations", numDels),
+		zap.Duration("duration", time.Since(start)),
+		zap.Any("stats", ce.Metrics()),
+	)
+}
@@ -0,0 +1,118 @@
+package covenant
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// backoffEntry records the last failed attempt of signing a delegation
+type backoffEntry struct {
+	lastAttempt time.Time
+	interval    time.Duration
+	cause       string
+}
+
+// delegationBackoff tracks the failed delegations by their staking tx hashes
+// so that persistently failing delegations are retried less frequently.
+// The retry interval of a delegation is doubled upon each failure with the
+// same cause, up to maxInterval, and is reset when the cause changes.
+type delegationBackoff struct {
+	mu          sync.Mutex
+	entries     map[string]*backoffEntry
+	minInterval time.Duration
+	maxInterval time.Duration
+}
+
+func newDelegationBackoff(minInterval, maxInterval time.Duration) *delegationBackoff {
+	return &delegationBackoff{
+		entries:     make(map[string]*backoffEntry),
+		minInterval: minInterval,
+		maxInterval: maxInterval,
+	}
+}
+
+// shouldSkip returns whether the delegation is still backing off from its last failure
+func (b *delegationBackoff) shouldSkip(stakingTxHash string, now time.Time) bool {
+	if b.minInterval == 0 {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry, ok := b.entries[stakingTxHash]
+	if !ok {
+		return false
+	}
+
+	return now.Before(entry.lastAttempt.Add(entry.interval))
+}
+
+// recordFailure records a failed attempt of the delegation with the given cause
+func (b *delegationBackoff) recordFailure(stakingTxHash string, cause string, now time.Time) {
+	if b.minInterval == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pruneExpired(now)
+
+	entry, ok := b.entries[stakingTxHash]
+	if !ok || entry.cause != cause {
+		b.entries[stakingTxHash] = &backoffEntry{
+			lastAttempt: now,
+			interval:    b.minInterval,
+			cause:       cause,
+		}
+		return
+	}
+
+	entry.lastAttempt = now
+	entry.interval *= 2
+	if entry.interval > b.maxInterval {
+		entry.interval = b.maxInterval
+	}
+}
+
+// recordSuccess clears the backoff of the delegation
+func (b *delegationBackoff) recordSuccess(stakingTxHash string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.entries, stakingTxHash)
+}
+
+// pruneExpired removes the entries that have not been attempted for longer
+// than maxInterval after their backoff elapsed, e.g., because the delegation
+// is no longer pending. It must be called with the lock held.
+func (b *delegationBackoff) pruneExpired(now time.Time) {
+	for hash, entry := range b.entries {
+		if now.After(entry.lastAttempt.Add(entry.interval + b.maxInterval)) {
+			delete(b.entries, hash)
+		}
+	}
+}
+
+// removeBackingOff removes any delegations that are still backing off
+// from their last failed attempt
+func (ce *CovenantEmulator) removeBackingOff(dels []*types.Delegation) []*types.Delegation {
+	now := time.Now()
+	ready := make([]*types.Delegation, 0, len(dels))
+	for _, del := range dels {
+		if hash, ok := stakingTxHashOf(del); ok && ce.backoff.shouldSkip(hash, now) {
+			ce.logger.Debug("the delegation is backing off from its last failure, deferring it",
+				zap.String("staking_tx_hash", hash),
+			)
+			continue
+		}
+		ready = append(ready, del)
+	}
+
+	return ready
+}
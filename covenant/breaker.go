@@ -0,0 +1,138 @@
+package covenant
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type breakerState int
+
+const (
+	// breakerClosed lets all the calls to the consumer chain through
+	breakerClosed breakerState = iota
+	// breakerOpen blocks the calls to the consumer chain until the cooldown elapses
+	breakerOpen
+	// breakerHalfOpen lets calls through to probe whether the consumer chain recovered
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerClosed:
+		return "closed"
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// circuitBreaker stops the emulator from querying and submitting to the consumer
+// chain for a cooldown after a number of consecutive failures. Once the cooldown
+// elapses, it half-opens so that the next round probes the consumer chain. A
+// success closes it while a failure opens it again.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	state     breakerState
+	failures  uint32
+	openedAt  time.Time
+	threshold uint32
+	cooldown  time.Duration
+}
+
+func newCircuitBreaker(threshold uint32, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow returns whether calls to the consumer chain are allowed
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerOpen && !now.Before(b.openedAt.Add(b.cooldown)) {
+		b.state = breakerHalfOpen
+	}
+
+	return b.state != breakerOpen
+}
+
+// recordResult updates the breaker with the result of a call to the
+// consumer chain and returns the new state
+func (b *circuitBreaker) recordResult(err error, now time.Time) breakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.state = breakerClosed
+		b.failures = 0
+		return b.state
+	}
+
+	b.failures++
+	switch b.state {
+	case breakerHalfOpen:
+		b.state = breakerOpen
+		b.openedAt = now
+	case breakerClosed:
+		if b.threshold != 0 && b.failures >= b.threshold {
+			b.state = breakerOpen
+			b.openedAt = now
+		}
+	}
+
+	return b.state
+}
+
+func (b *circuitBreaker) getState() breakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// recordClientResult feeds the result of a call to the client controller to the
+// circuit breaker. It also tracks the consecutive failures and reconnects the
+// client controller once they reach the configured limit
+func (ce *CovenantEmulator) recordClientResult(err error) {
+	ce.clientMu.Lock()
+	defer ce.clientMu.Unlock()
+
+	prevState := ce.breaker.getState()
+	state := ce.breaker.recordResult(err, time.Now())
+	if state != prevState {
+		ce.metrics.ClientBreakerState.Set(float64(state))
+		ce.logger.Warn("the circuit breaker around the consumer chain client changed its state",
+			zap.Stringer("from", prevState),
+			zap.Stringer("to", state),
+		)
+	}
+
+	if err == nil {
+		ce.clientFailures.Store(0)
+		return
+	}
+
+	limit := ce.config.MaxClientFailures
+	failures := ce.clientFailures.Add(1)
+	if limit == 0 || failures < limit {
+		return
+	}
+
+	ce.logger.Warn("reconnecting to the consumer chain due to repeated failures",
+		zap.Uint32("consecutive_failures", failures),
+		zap.Error(err),
+	)
+	if err := ce.cc.Reconnect(); err != nil {
+		ce.logger.Error("failed to reconnect to the consumer chain", zap.Error(err))
+		return
+	}
+	ce.clientFailures.Store(0)
+	ce.logger.Info("successfully reconnected to the consumer chain")
+}
@@ -0,0 +1,127 @@
+package covenant
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// signDelegations returns the function which returns the covenant sigs of the
+// i-th of the given delegations against the given params. If concurrent signing is enabled, those to
+// sign are signed upfront by at most the configured number of goroutines.
+// Otherwise each is signed upon the call, so that the delegations after a
+// failed one are not signed in vain.
+func (ce *CovenantEmulator) signDelegations(
+	btcDels []*types.Delegation,
+	params *types.StakingParams,
+	loggers []*zap.Logger,
+	toSign []bool,
+) func(i int) (*types.CovenantSigs, error) {
+	signOne := func(i int) (*types.CovenantSigs, error) {
+		trace := ce.newDecisionTrace()
+		covSigs, err := ce.signDelegation(btcDels[i], params, loggers[i], trace)
+		ce.writeDecisionTrace(trace, err)
+		return covSigs, err
+	}
+
+	concurrency := int(ce.config.MaxConcurrentSigning)
+	if concurrency <= 1 {
+		return signOne
+	}
+
+	covSigs := make([]*types.CovenantSigs, len(btcDels))
+	errs := make([]error, len(btcDels))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range btcDels {
+		if !toSign[i] {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			covSigs[i], errs[i] = signOne(i)
+		}(i)
+	}
+	wg.Wait()
+
+	return func(i int) (*types.CovenantSigs, error) {
+		return covSigs[i], errs[i]
+	}
+}
+
+// submitBatches signs and submits the given batches of delegations, by at most
+// the configured number of goroutines, and returns the errors of the batches
+// in order. Without concurrent submissions, it stops after the batch of which
+// the error stops the submission loop, so fewer errors than batches are
+// returned.
+func (ce *CovenantEmulator) submitBatches(batches [][]*types.Delegation) []error {
+	submitOne := func(delBatch []*types.Delegation, recordClientResult func(err error)) error {
+		res, counts, err := ce.addCovenantSignatures(delBatch, recordClientResult)
+		if err == nil && res != nil {
+			err = ce.confirmSubmission(counts.submitted)
+		}
+		return err
+	}
+
+	concurrency := int(ce.config.MaxConcurrentSubmissions)
+	if concurrency <= 1 {
+		errs := make([]error, 0, len(batches))
+		for _, delBatch := range batches {
+			err := submitOne(delBatch, ce.recordClientResult)
+			errs = append(errs, err)
+			if ce.stopsSubmissionLoop(err) {
+				break
+			}
+		}
+		return errs
+	}
+
+	errs := make([]error, len(batches))
+	clientResults := make([][]error, len(batches))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, delBatch := range batches {
+		select {
+		case <-ce.quit:
+			errs[i] = ErrShuttingDown
+			continue
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(i int, delBatch []*types.Delegation) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = submitOne(delBatch, func(err error) {
+				clientResults[i] = append(clientResults[i], err)
+			})
+		}(i, delBatch)
+	}
+	wg.Wait()
+
+	// the results are recorded once the submissions are drained, as a
+	// reconnect closes the connection used by the submissions in flight
+	for _, results := range clientResults {
+		for _, err := range results {
+			ce.recordClientResult(err)
+		}
+	}
+
+	return errs
+}
+
+// submitCovenantSigs submits the given sigs through the client controller,
+// one tx at a time as all of them are signed by the same submitter account
+func (ce *CovenantEmulator) submitCovenantSigs(covenantSigs []*types.CovenantSigs) (*types.TxResponse, error) {
+	ce.submitMu.Lock()
+	defer ce.submitMu.Unlock()
+
+	ctx, cancel := ce.quitContext()
+	defer cancel()
+
+	return ce.cc.SubmitCovenantSigs(ctx, covenantSigs)
+}
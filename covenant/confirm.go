@@ -0,0 +1,113 @@
+package covenant
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/clientcontroller"
+	covcfg "github.com/babylonchain/covenant-emulator/config"
+)
+
+const (
+	// maxConfirmPollInterval caps the backoff between the polls for the
+	// confirmation of submitted sigs
+	maxConfirmPollInterval = 10 * time.Second
+
+	confirmationConfirmed   = "confirmed"
+	confirmationUnconfirmed = "unconfirmed"
+)
+
+// WaitPollInterval is the initial interval of polling the consumer chain when
+// waiting for the inclusion of the submitted covenant signatures
+var WaitPollInterval = 500 * time.Millisecond
+
+// waitForConfirmation polls the consumer chain with exponential backoff, starting
+// at WaitPollInterval, until the delegation with the given staking tx hash
+// includes our sigs or reaches a covenant quorum. It returns ErrSubmittedUnconfirmed
+// if neither happens within the given timeout or max attempts (0 means no limit).
+func (ce *CovenantEmulator) waitForConfirmation(stakingTxHash chainhash.Hash, timeout time.Duration, maxAttempts uint32) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	interval := WaitPollInterval
+	var attempts uint32
+	for {
+		select {
+		case <-time.After(interval):
+		case <-timer.C:
+			ce.metrics.SubmissionConfirmations.WithLabelValues(confirmationUnconfirmed).Inc()
+			return fmt.Errorf("%w: delegation %s, timeout %v", ErrSubmittedUnconfirmed, stakingTxHash.String(), timeout)
+		case <-ce.quit:
+			return ErrShuttingDown
+		}
+
+		attempts++
+		del, err := ce.cc.QueryBTCDelegation(stakingTxHash)
+		if errors.Is(err, clientcontroller.ErrDelegationNotFound) {
+			ce.recordVanished(stakingTxHash.String(), ce.logger)
+			return err
+		}
+		if err != nil {
+			ce.logger.Debug("failed to query the delegation",
+				zap.String("staking_tx_hash", stakingTxHash.String()),
+				zap.Error(err),
+			)
+		} else if ce.isSignedByUs(del) || del.HasCovenantQuorum(ce.params.Load().CovenantQuorum) {
+			ce.metrics.SubmissionConfirmations.WithLabelValues(confirmationConfirmed).Inc()
+			return nil
+		}
+
+		if maxAttempts != 0 && attempts >= maxAttempts {
+			ce.metrics.SubmissionConfirmations.WithLabelValues(confirmationUnconfirmed).Inc()
+			return fmt.Errorf("%w: delegation %s, %d attempts", ErrSubmittedUnconfirmed, stakingTxHash.String(), attempts)
+		}
+
+		interval *= 2
+		if interval > maxConfirmPollInterval {
+			interval = maxConfirmPollInterval
+		}
+	}
+}
+
+// confirmSubmission waits for the sigs submitted for the delegations with the
+// given staking tx hashes to be confirmed as configured. It returns an error
+// only if the confirmation is required and some sigs are not confirmed, which
+// are then retried later.
+func (ce *CovenantEmulator) confirmSubmission(stakingTxHashes []chainhash.Hash) error {
+	mode := ce.config.SubmissionConfirmation
+	if mode == covcfg.SubmissionConfirmationNone {
+		return nil
+	}
+
+	var numUnconfirmed int
+	now := time.Now()
+	for _, stakingTxHash := range stakingTxHashes {
+		hash := stakingTxHash.String()
+		err := ce.waitForConfirmation(stakingTxHash, ce.config.ConfirmationTimeout, ce.config.ConfirmationMaxAttempts)
+		if errors.Is(err, ErrShuttingDown) {
+			return err
+		}
+		if !errors.Is(err, ErrSubmittedUnconfirmed) {
+			continue
+		}
+		numUnconfirmed++
+		ce.logger.Warn("the submitted covenant signatures are not confirmed",
+			zap.String("staking_tx_hash", hash),
+			zap.String("confirmation", mode),
+			zap.Error(err),
+		)
+		if mode == covcfg.SubmissionConfirmationRequired {
+			ce.backoff.recordFailure(hash, ErrSubmittedUnconfirmed.Error(), now)
+		}
+	}
+
+	if mode == covcfg.SubmissionConfirmationRequired && numUnconfirmed > 0 {
+		return fmt.Errorf("%w: %d of %d delegations", ErrSubmittedUnconfirmed, numUnconfirmed, len(stakingTxHashes))
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package covenant
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxStandardTxVersion is the highest tx version relayed by default
+	maxStandardTxVersion = 2
+	// maxStandardTxWeight is the maximum weight of a tx relayed by default
+	maxStandardTxWeight = 400000
+)
+
+// checkConsensusRules runs the given tx through the context-free BTC consensus
+// checks, i.e., non-empty inputs and outputs, output values within range, and
+// no duplicate inputs, and through the standardness checks of the tx version,
+// weight, and output scripts. The failing check is logged with the tx type.
+func checkConsensusRules(logger *zap.Logger, txType string, tx *wire.MsgTx) error {
+	check, err := consensusRuleViolation(tx)
+	if err == nil {
+		return nil
+	}
+
+	logger.Warn("the tx of the delegation violates BTC consensus or standardness rules",
+		zap.String("tx_type", txType),
+		zap.String("tx_hash", tx.TxHash().String()),
+		zap.String("check", check),
+		zap.Error(err),
+	)
+
+	return fmt.Errorf("%w: the %s tx fails the %s check: %w", ErrTxRuleViolation, txType, check, err)
+}
+
+// consensusRuleViolation returns the name of the first failing check and its
+// error, or a nil error if the tx passes all the checks
+func consensusRuleViolation(tx *wire.MsgTx) (string, error) {
+	if err := blockchain.CheckTransactionSanity(btcutil.NewTx(tx)); err != nil {
+		return "sanity", err
+	}
+
+	if tx.Version < 1 || tx.Version > maxStandardTxVersion {
+		return "version", fmt.Errorf("tx version %d is not in the standard range [1, %d]",
+			tx.Version, maxStandardTxVersion)
+	}
+
+	if weight := blockchain.GetTransactionWeight(btcutil.NewTx(tx)); weight > maxStandardTxWeight {
+		return "weight", fmt.Errorf("tx weight %d exceeds the standard maximum %d",
+			weight, maxStandardTxWeight)
+	}
+
+	for i, out := range tx.TxOut {
+		if txscript.GetScriptClass(out.PkScript) == txscript.NonStandardTy {
+			return "output_script", fmt.Errorf("output %d has a non-standard script", i)
+		}
+	}
+
+	return "", nil
+}
@@ -1,28 +1,23 @@
 package covenant
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/avast/retry-go/v4"
 	"github.com/btcsuite/btcd/btcec/v2"
-
 	"go.uber.org/zap"
 
+	"github.com/babylonchain/covenant-emulator/clientcontroller"
 	covcfg "github.com/babylonchain/covenant-emulator/config"
 	"github.com/babylonchain/covenant-emulator/keyring"
-
-	"github.com/babylonchain/babylon/btcstaking"
-	asig "github.com/babylonchain/babylon/crypto/schnorr-adaptor-signature"
-	bbntypes "github.com/babylonchain/babylon/types"
-	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
-	"github.com/btcsuite/btcd/btcutil"
-
-	"github.com/babylonchain/covenant-emulator/clientcontroller"
+	"github.com/babylonchain/covenant-emulator/log"
+	"github.com/babylonchain/covenant-emulator/metrics"
 	"github.com/babylonchain/covenant-emulator/types"
 )
 
@@ -46,37 +41,118 @@ type CovenantEmulator struct {
 	cc clientcontroller.ClientController
 	kc *keyring.ChainKeyringController
 
-	config *covcfg.Config
-	params *types.StakingParams
-	logger *zap.Logger
+	config  *covcfg.Config
+	logger  *zap.Logger
+	metrics *metrics.CovenantMetrics
+
+	// params are the staking params last queried, which are replaced by the
+	// loop while the public APIs read them, so each pass takes one snapshot
+	params atomic.Pointer[types.StakingParams]
+	// paramsMu serializes updating the params, which the public APIs do
+	// concurrently with the loop, and guards paramsUpdatedAt
+	paramsMu sync.Mutex
+
+	// paramsUpdatedAt is the time at which params are last queried
+	paramsUpdatedAt time.Time
+	// lastReconcile is the time at which the submitted sigs are last reconciled
+	lastReconcile time.Time
+	// paramsVersion is increased whenever the queried params differ from
+	// the previous ones, so that sigs computed against stale params are discarded
+	paramsVersion atomic.Uint64
+
+	// keyringUnlockFailures counts the consecutive failures to get
+	// the covenant private key from the keyring
+	keyringUnlockFailures atomic.Uint32
+	// keyringMu serializes the access to the keyring, which passes the
+	// passphrase through a shared reader
+	keyringMu sync.Mutex
+
+	// clientFailures counts the consecutive failures to query
+	// or submit to the consumer chain
+	clientFailures atomic.Uint32
+	// clientMu serializes recording the results of the client, which
+	// may reconnect it
+	clientMu sync.Mutex
+	// submitMu serializes the broadcasts of the submitter account, of
+	// which concurrent txs would collide on the account sequence
+	submitMu sync.Mutex
+
+	stats signingStats
+
+	backoff    *delegationBackoff
+	breaker    *circuitBreaker
+	unsignable unsignableSet
+	heartbeat  *heartbeater
+	cursor     *delegationCursor
+	results    chan<- DelegationResult
+	sink       ResultSink
+	traces     *traceWriter
+
+	paramsChangeLog paramsChangeLog
+	emptyTickLog    emptyTickLog
+	schedule        processingSchedule
+	submissions     submissionTracker
+
+	// paramsInvalid is set while the staking params on the consumer chain
+	// are invalid, during which signing is halted
+	paramsInvalid bool
+
+	// reorged keeps the delegations found reorged out, so that each of
+	// them is logged once
+	reorged unsignableSet
+	// sweep collects the pending delegations across pages, against which
+	// unsignable and reorged are pruned
+	sweep pendingSweep
+
+	encKeyDeriver  EncKeyDeriver
+	paramsProvider ParamsProvider
+	// spendPathSelector selects the spend paths which are signed
+	spendPathSelector SpendPathSelector
+
+	// standby is set if the emulator computes but does not submit signatures
+	standby     atomic.Bool
+	standbySigs standbySigs
+
+	// filters are run in order on every delegation before signing it
+	filters []DelegationFilter
 
 	// input is used to pass passphrase to the keyring
 	input      *strings.Reader
 	passphrase string
 }
 
+// NewCovenantEmulator creates a covenant emulator. If the given logger is nil,
+// a logger is created from the log settings in the config.
 func NewCovenantEmulator(
 	config *covcfg.Config,
 	cc clientcontroller.ClientController,
 	passphrase string,
 	logger *zap.Logger,
 ) (*CovenantEmulator, error) {
-	input := strings.NewReader("")
-	kr, err := keyring.CreateKeyring(
-		config.BabylonConfig.KeyDirectory,
-		config.BabylonConfig.ChainID,
-		config.BabylonConfig.KeyringBackend,
-		input,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create keyring: %w", err)
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
-	kc, err := keyring.NewChainKeyringControllerWithKeyring(kr, config.BabylonConfig.Key, input)
+	if logger == nil {
+		var err error
+		logger, err = newLoggerFromConfig(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	input := strings.NewReader("")
+	kc, err := initKeyring(config, input, logger)
 	if err != nil {
 		return nil, err
 	}
 
+	if config.CreateKeyIfMissing {
+		if err := createKeyIfMissing(kc, config.BabylonConfig.Key, passphrase, logger); err != nil {
+			return nil, err
+		}
+	}
+
 	sk, err := kc.GetChainPrivKey(passphrase)
 	if err != nil {
 		return nil, fmt.Errorf("covenant key %s is not found: %w", config.BabylonConfig.Key, err)
@@ -87,384 +163,121 @@ func NewCovenantEmulator(
 		return nil, err
 	}
 
-	return &CovenantEmulator{
-		cc:         cc,
-		kc:         kc,
-		config:     config,
-		logger:     logger,
-		input:      input,
-		passphrase: passphrase,
-		pk:         pk,
-		quit:       make(chan struct{}),
-	}, nil
-}
-
-func (ce *CovenantEmulator) UpdateParams() error {
-	params, err := ce.getParamsWithRetry()
+	ce := &CovenantEmulator{
+		cc:             cc,
+		kc:             kc,
+		config:         config,
+		logger:         logger,
+		metrics:        metrics.NewCovenantMetrics(),
+		backoff:        newDelegationBackoff(config.MinRetryInterval, config.MaxRetryInterval),
+		breaker:        newCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown),
+		cursor:         newDelegationCursor(config.DelegationCursorFile, logger),
+		heartbeat:      newHeartbeater(config.Heartbeat, logger),
+		encKeyDeriver:  DefaultEncKeyDeriver,
+		paramsProvider: newClientParamsProvider(cc, logger),
+		input:          input,
+		passphrase:     passphrase,
+		pk:             pk,
+		quit:           make(chan struct{}),
+	}
+	ce.paramsChangeLog.interval = config.ParamsChangeLogInterval
+	ce.emptyTickLog.mode = config.EmptyTickLog
+	ce.emptyTickLog.interval = config.EmptyTickSummaryInterval
+	ce.spendPathSelector = DefaultSpendPathSelector
+	ce.filters = []DelegationFilter{
+		StakingTimeFilter{Min: config.MinStakingTime, Max: config.MaxStakingTime},
+	}
+	ce.schedule, err = newProcessingSchedule(config.ProcessingWindows, config.OffWindowExpiryBlocks)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	ce.params = params
-
-	return nil
-}
-
-// AddCovenantSignatures adds a Covenant signature on the given Bitcoin delegation and submits it to Babylon
-// TODO: break this function into smaller components
-func (ce *CovenantEmulator) AddCovenantSignatures(btcDels []*types.Delegation) (*types.TxResponse, error) {
-	if len(btcDels) == 0 {
-		return nil, fmt.Errorf("no delegations")
+	if config.DecisionTraceFile != "" {
+		ce.traces = &traceWriter{path: config.DecisionTraceFile}
 	}
-	covenantSigs := make([]*types.CovenantSigs, 0, len(btcDels))
-	for _, btcDel := range btcDels {
-		// 0. nil checks
-		if btcDel == nil {
-			return nil, fmt.Errorf("empty delegation")
-		}
-
-		if btcDel.BtcUndelegation == nil {
-			return nil, fmt.Errorf("empty undelegation")
-		}
-
-		// 1. the quorum is already achieved, skip sending more sigs
-		if btcDel.HasCovenantQuorum(ce.params.CovenantQuorum) {
-			return nil, nil
-		}
-
-		// 2. check unbonding time (staking time from unbonding tx) is larger than min unbonding time
-		// which is larger value from:
-		// - MinUnbondingTime
-		// - CheckpointFinalizationTimeout
-		unbondingTime := btcDel.UnbondingTime
-		minUnbondingTime := ce.params.MinUnbondingTime
-		if unbondingTime <= minUnbondingTime {
-			return nil, fmt.Errorf("unbonding time %d must be larger than %d",
-				unbondingTime, minUnbondingTime)
-		}
-
-		// 3. check staking tx and slashing tx are valid
-		stakingMsgTx, _, err := bbntypes.NewBTCTxFromHex(btcDel.StakingTxHex)
+	ce.sink = NoopResultSink{}
+	if config.ResultsDatabase != "" {
+		sink, err := NewSQLResultSink(config.ResultsDatabaseDriver, config.ResultsDatabase)
 		if err != nil {
 			return nil, err
 		}
-
-		slashingTx, err := bstypes.NewBTCSlashingTxFromHex(btcDel.SlashingTxHex)
-		if err != nil {
-			return nil, err
-		}
-
-		slashingMsgTx, err := slashingTx.ToMsgTx()
-		if err != nil {
-			return nil, err
-		}
-
-		if err := btcstaking.CheckTransactions(
-			slashingMsgTx,
-			stakingMsgTx,
-			btcDel.StakingOutputIdx,
-			int64(ce.params.MinSlashingTxFeeSat),
-			ce.params.SlashingRate,
-			ce.params.SlashingAddress,
-			btcDel.BtcPk,
-			uint16(unbondingTime),
-			&ce.config.BTCNetParams,
-		); err != nil {
-			return nil, fmt.Errorf("invalid txs in the delegation: %w", err)
-		}
-
-		// 4. Check unbonding transaction
-		unbondingSlashingMsgTx, _, err := bbntypes.NewBTCTxFromHex(btcDel.BtcUndelegation.SlashingTxHex)
-		if err != nil {
-			return nil, err
-		}
-
-		unbondingMsgTx, _, err := bbntypes.NewBTCTxFromHex(btcDel.BtcUndelegation.UnbondingTxHex)
-		if err != nil {
-			return nil, err
-		}
-
-		unbondingInfo, err := btcstaking.BuildUnbondingInfo(
-			btcDel.BtcPk,
-			btcDel.FpBtcPks,
-			ce.params.CovenantPks,
-			ce.params.CovenantQuorum,
-			uint16(unbondingTime),
-			btcutil.Amount(unbondingMsgTx.TxOut[0].Value),
-			&ce.config.BTCNetParams,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		err = btcstaking.CheckTransactions(
-			unbondingSlashingMsgTx,
-			unbondingMsgTx,
-			0,
-			int64(ce.params.MinSlashingTxFeeSat),
-			ce.params.SlashingRate,
-			ce.params.SlashingAddress,
-			btcDel.BtcPk,
-			uint16(unbondingTime),
-			&ce.config.BTCNetParams,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("invalid txs in the undelegation: %w", err)
-		}
-
-		// 5. sign covenant staking sigs
-		covenantPrivKey, err := ce.getPrivKey()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get Covenant private key: %w", err)
-		}
-
-		stakingInfo, err := btcstaking.BuildStakingInfo(
-			btcDel.BtcPk,
-			btcDel.FpBtcPks,
-			ce.params.CovenantPks,
-			ce.params.CovenantQuorum,
-			btcDel.GetStakingTime(),
-			btcutil.Amount(btcDel.TotalSat),
-			&ce.config.BTCNetParams,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		slashingPathInfo, err := stakingInfo.SlashingPathSpendInfo()
-		if err != nil {
-			return nil, err
-		}
-
-		covSigs := make([][]byte, 0, len(btcDel.FpBtcPks))
-		for _, valPk := range btcDel.FpBtcPks {
-			encKey, err := asig.NewEncryptionKeyFromBTCPK(valPk)
-			if err != nil {
-				return nil, err
-			}
-			covenantSig, err := slashingTx.EncSign(
-				stakingMsgTx,
-				btcDel.StakingOutputIdx,
-				slashingPathInfo.GetPkScriptPath(),
-				covenantPrivKey,
-				encKey,
-			)
-			if err != nil {
-				return nil, err
-			}
-			covSigs = append(covSigs, covenantSig.MustMarshal())
-		}
-
-		// 6. sign covenant unbonding sig
-		stakingTxUnbondingPathInfo, err := stakingInfo.UnbondingPathSpendInfo()
-		if err != nil {
-			return nil, err
-		}
-		covenantUnbondingSignature, err := btcstaking.SignTxWithOneScriptSpendInputStrict(
-			unbondingMsgTx,
-			stakingMsgTx,
-			btcDel.StakingOutputIdx,
-			stakingTxUnbondingPathInfo.GetPkScriptPath(),
-			covenantPrivKey,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to sign unbonding tx: %w", err)
-		}
-
-		// 7. sign covenant unbonding slashing sig
-		slashUnbondingTx, err := bstypes.NewBTCSlashingTxFromHex(btcDel.BtcUndelegation.SlashingTxHex)
-		if err != nil {
-			return nil, err
-		}
-
-		unbondingTxSlashingPath, err := unbondingInfo.SlashingPathSpendInfo()
-		if err != nil {
-			return nil, err
-		}
-
-		covSlashingSigs := make([][]byte, 0, len(btcDel.FpBtcPks))
-		for _, fpPk := range btcDel.FpBtcPks {
-			encKey, err := asig.NewEncryptionKeyFromBTCPK(fpPk)
-			if err != nil {
-				return nil, err
-			}
-			covenantSig, err := slashUnbondingTx.EncSign(
-				unbondingMsgTx,
-				0, // 0th output is always the unbonding script output
-				unbondingTxSlashingPath.GetPkScriptPath(),
-				covenantPrivKey,
-				encKey,
-			)
-			if err != nil {
-				return nil, err
-			}
-			covSlashingSigs = append(covSlashingSigs, covenantSig.MustMarshal())
-		}
-
-		// 8. collect covenant sigs
-		covenantSigs = append(covenantSigs, &types.CovenantSigs{
-			PublicKey:             ce.pk,
-			StakingTxHash:         stakingMsgTx.TxHash(),
-			SlashingSigs:          covSigs,
-			UnbondingSig:          covenantUnbondingSignature,
-			SlashingUnbondingSigs: covSlashingSigs,
-		})
+		ce.sink = sink
 	}
-	// 9. submit covenant sigs
-	return ce.cc.SubmitCovenantSigs(covenantSigs)
-}
-
-func (ce *CovenantEmulator) getPrivKey() (*btcec.PrivateKey, error) {
-	sdkPrivKey, err := ce.kc.GetChainPrivKey(ce.passphrase)
-	if err != nil {
-		return nil, err
+	if config.Standby {
+		ce.standby.Store(true)
+		ce.metrics.Standby.Set(1)
 	}
 
-	privKey, _ := btcec.PrivKeyFromBytes(sdkPrivKey.Key)
-
-	return privKey, nil
+	return ce, nil
 }
 
-// delegationsToBatches takes a list of delegations and splits them into batches
-func (ce *CovenantEmulator) delegationsToBatches(dels []*types.Delegation) [][]*types.Delegation {
-	batchSize := ce.config.SigsBatchSize
-	batches := make([][]*types.Delegation, 0)
-
-	for i := uint64(0); i < uint64(len(dels)); i += batchSize {
-		end := i + batchSize
-		if end > uint64(len(dels)) {
-			end = uint64(len(dels))
-		}
-		batches = append(batches, dels[i:end])
+// newLoggerFromConfig creates a logger writing to stderr with the log format,
+// level, and sampling in the given config
+func newLoggerFromConfig(config *covcfg.Config) (*zap.Logger, error) {
+	logger, err := log.NewRootLogger(config.LogFormat, config.LogLevel, os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logger: %w", err)
+	}
+	if config.LogSampling {
+		logger = log.WithSampling(logger)
 	}
 
-	return batches
+	return logger, nil
 }
 
-// removeAlreadySigned removes any delegations that have already been signed by the covenant
-func (ce *CovenantEmulator) removeAlreadySigned(dels []*types.Delegation) []*types.Delegation {
-	sanitized := make([]*types.Delegation, 0, len(dels))
-
-	for _, del := range dels {
-		delCopy := del
-		alreadySigned := false
-		for _, covSig := range delCopy.CovenantSigs {
-			if bytes.Equal(schnorr.SerializePubKey(covSig.Pk), schnorr.SerializePubKey(ce.pk)) {
-				alreadySigned = true
-				break
-			}
-		}
-		if !alreadySigned {
-			sanitized = append(sanitized, delCopy)
-		}
-	}
-	return sanitized
+// PublicKey returns the pk of the covenant key of the emulator
+func (ce *CovenantEmulator) PublicKey() *btcec.PublicKey {
+	return ce.pk
 }
 
-// covenantSigSubmissionLoop is the reactor to submit Covenant signature for BTC delegations
-func (ce *CovenantEmulator) covenantSigSubmissionLoop() {
-	defer ce.wg.Done()
+func (ce *CovenantEmulator) Start() error {
+	var startErr error
+	ce.startOnce.Do(func() {
+		ce.logger.Info("Starting Covenant Emulator")
 
-	interval := ce.config.QueryInterval
-	limit := ce.config.DelegationLimit
-	covenantSigTicker := time.NewTicker(interval)
+		if ce.config.OnlyDecidingSig {
+			ce.logger.Warn("only signing delegations that our signature brings to the covenant quorum, " +
+				"delegations are never activated if the rest of the committee does not sign them first")
+		}
 
-	for {
-		select {
-		case <-covenantSigTicker.C:
-			// 0. Update slashing address in case it is changed upon governance proposal
+		if ce.config.RequireParamsOnStart {
 			if err := ce.UpdateParams(); err != nil {
-				ce.logger.Debug("failed to get staking params", zap.Error(err))
-				continue
+				startErr = fmt.Errorf("failed to get staking params on start: %w", err)
+				return
 			}
+		}
 
-			// 1. Get all pending delegations
-			dels, err := ce.cc.QueryPendingDelegations(limit)
-			if err != nil {
-				ce.logger.Debug("failed to get pending delegations", zap.Error(err))
-				continue
+		if ce.config.PprofAddress != "" {
+			if err := ce.startPprofServer(); err != nil {
+				startErr = err
+				return
 			}
-			if len(dels) == 0 {
-				ce.logger.Debug("no pending delegations are found")
-			}
-			// 2. Remove delegations that do not need the covenant's signature
-			sanitizedDels := ce.removeAlreadySigned(dels)
-
-			// 3. Split delegations into batches for submission
-			batches := ce.delegationsToBatches(sanitizedDels)
-			for _, delBatch := range batches {
-				_, err := ce.AddCovenantSignatures(delBatch)
-				if err != nil {
-					ce.logger.Error(
-						"failed to submit covenant signatures for BTC delegations",
-						zap.Error(err),
-					)
-				}
-			}
-
-		case <-ce.quit:
-			ce.logger.Debug("exiting covenant signature submission loop")
-			return
 		}
-	}
 
-}
-
-func CreateCovenantKey(keyringDir, chainID, keyName, backend, passphrase, hdPath string) (*types.ChainKeyInfo, error) {
-	sdkCtx, err := keyring.CreateClientCtx(
-		keyringDir, chainID,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	krController, err := keyring.NewChainKeyringController(
-		sdkCtx,
-		keyName,
-		backend,
-	)
-	if err != nil {
-		return nil, err
-	}
+		ce.wg.Add(1)
+		go func() {
+			if ce.config.Backfill {
+				ce.backfill()
+			}
+			ce.covenantSigSubmissionLoop()
+		}()
+	})
 
-	return krController.CreateChainKey(passphrase, hdPath)
+	return startErr
 }
 
-func (ce *CovenantEmulator) getParamsWithRetry() (*types.StakingParams, error) {
-	var (
-		params *types.StakingParams
-		err    error
-	)
-
-	if err := retry.Do(func() error {
-		params, err = ce.cc.QueryStakingParams()
-		if err != nil {
-			return err
+// quitContext returns a context which is done once the emulator is stopped,
+// so that the calls to the client controller return on shutdown
+func (ce *CovenantEmulator) quitContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-ce.quit:
+			cancel()
+		case <-ctx.Done():
 		}
-		return nil
-	}, RtyAtt, RtyDel, RtyErr, retry.OnRetry(func(n uint, err error) {
-		ce.logger.Debug(
-			"failed to query the consumer chain for the staking params",
-			zap.Uint("attempt", n+1),
-			zap.Uint("max_attempts", RtyAttNum),
-			zap.Error(err),
-		)
-	})); err != nil {
-		return nil, err
-	}
-
-	return params, nil
-}
-
-func (ce *CovenantEmulator) Start() error {
-	var startErr error
-	ce.startOnce.Do(func() {
-		ce.logger.Info("Starting Covenant Emulator")
+	}()
 
-		ce.wg.Add(1)
-		go ce.covenantSigSubmissionLoop()
-	})
-
-	return startErr
+	return ctx, cancel
 }
 
 func (ce *CovenantEmulator) Stop() error {
@@ -477,6 +290,10 @@ func (ce *CovenantEmulator) Stop() error {
 		close(ce.quit)
 		ce.wg.Wait()
 
+		if err := ce.sink.Close(); err != nil {
+			stopErr = fmt.Errorf("failed to close the result sink: %w", err)
+		}
+
 		ce.logger.Debug("Covenant Emulator successfully stopped")
 	})
 	return stopErr
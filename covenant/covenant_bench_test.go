@@ -0,0 +1,104 @@
+package covenant_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/babylonchain/babylon/btcstaking"
+	asig "github.com/babylonchain/babylon/crypto/schnorr-adaptor-signature"
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	covcfg "github.com/babylonchain/covenant-emulator/config"
+	"github.com/babylonchain/covenant-emulator/testutil"
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+var benchFpNums = []int{1, 5, 10, 20}
+
+// BenchmarkEncSign benchmarks producing the covenant adaptor signatures on the
+// staking slashing tx, one per finality provider
+func BenchmarkEncSign(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, b)
+	covSK, _, err := datagen.GenRandomBTCKeyPair(r)
+	require.NoError(b, err)
+
+	for _, fpNum := range benchFpNums {
+		bd := genTestDelegation(b, r, params, fpNum)
+		b.Run(fmt.Sprintf("fps=%d", fpNum), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				covSigs := make([][]byte, 0, len(bd.del.FpBtcPks))
+				for _, fpPk := range bd.del.FpBtcPks {
+					encKey, err := asig.NewEncryptionKeyFromBTCPK(fpPk)
+					require.NoError(b, err)
+					covenantSig, err := bd.stakingInfo.SlashingTx.EncSign(
+						bd.stakingTxMsg,
+						0,
+						bd.stakingSlashing.GetPkScriptPath(),
+						covSK,
+						encKey,
+					)
+					require.NoError(b, err)
+					covSigs = append(covSigs, covenantSig.MustMarshal())
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkSignUnbondingTx benchmarks producing the covenant Schnorr signature
+// on the unbonding tx via the unbonding path of the staking output
+func BenchmarkSignUnbondingTx(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, b)
+	covSK, _, err := datagen.GenRandomBTCKeyPair(r)
+	require.NoError(b, err)
+	bd := genTestDelegation(b, r, params, 1)
+	unbondingPathInfo, err := bd.stakingInfo.StakingInfo.UnbondingPathSpendInfo()
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := btcstaking.SignTxWithOneScriptSpendInputStrict(
+			bd.unbondingTxMsg,
+			bd.stakingTxMsg,
+			bd.del.StakingOutputIdx,
+			unbondingPathInfo.GetPkScriptPath(),
+			covSK,
+		)
+		require.NoError(b, err)
+	}
+}
+
+// BenchmarkAddCovenantSignatures benchmarks the full path of validating, signing,
+// and submitting a single delegation with a mocked submitter, with and without
+// pooling the tx decoding buffers
+func BenchmarkAddCovenantSignatures(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, b)
+	mockClientController := testutil.PrepareMockedClientController(b, params)
+	mockClientController.EXPECT().SubmitCovenantSigs(gomock.Any(), gomock.Any()).
+		Return(&types.TxResponse{TxHash: testutil.GenRandomHexStr(r, 32)}, nil).AnyTimes()
+
+	for _, poolTxBuffers := range []bool{false, true} {
+		covenantConfig := covcfg.DefaultConfig()
+		covenantConfig.PoolTxBuffers = poolTxBuffers
+		ce := newTestEmulatorWithConfig(b, mockClientController, &covenantConfig)
+
+		for _, fpNum := range benchFpNums {
+			bd := genTestDelegation(b, r, params, fpNum)
+			b.Run(fmt.Sprintf("pool=%t/fps=%d", poolTxBuffers, fpNum), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					_, err := ce.AddCovenantSignatures([]*types.Delegation{bd.del})
+					require.NoError(b, err)
+				}
+			})
+		}
+	}
+}
@@ -1,22 +1,41 @@
 package covenant_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/babylonchain/babylon/btcstaking"
 	asig "github.com/babylonchain/babylon/crypto/schnorr-adaptor-signature"
 	"github.com/babylonchain/babylon/testutil/datagen"
 	bbntypes "github.com/babylonchain/babylon/types"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
+	sdkcrypto "github.com/cosmos/cosmos-sdk/crypto"
+	sdksecp256k1 "github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/babylonchain/covenant-emulator/clientcontroller"
 	covcfg "github.com/babylonchain/covenant-emulator/config"
 	"github.com/babylonchain/covenant-emulator/covenant"
 	"github.com/babylonchain/covenant-emulator/testutil"
+	"github.com/babylonchain/covenant-emulator/testutil/fakeclient"
 	"github.com/babylonchain/covenant-emulator/types"
 )
 
@@ -182,10 +201,1147 @@ func FuzzAddCovenantSig(f *testing.F) {
 
 		// check the sigs are expected
 		expectedTxHash := testutil.GenRandomHexStr(r, 32)
-		mockClientController.EXPECT().SubmitCovenantSigs(covSigsSet).
+		mockClientController.EXPECT().SubmitCovenantSigs(gomock.Any(), covSigsSet).
 			Return(&types.TxResponse{TxHash: expectedTxHash}, nil).AnyTimes()
 		res, err := ce.AddCovenantSignatures(btcDels)
 		require.NoError(t, err)
 		require.Equal(t, expectedTxHash, res.TxHash)
 	})
 }
+
+// testDelegation is a valid delegation together with the info used to generate it
+type testDelegation struct {
+	del             *types.Delegation
+	stakingInfo     *datagen.TestStakingSlashingInfo
+	stakingTxMsg    *wire.MsgTx
+	unbondingTxMsg  *wire.MsgTx
+	stakingSlashing *btcstaking.SpendInfo
+}
+
+// genTestDelegation generates a valid delegation to the given number of finality providers
+func genTestDelegation(t testing.TB, r *rand.Rand, params *types.StakingParams, fpNum int) *testDelegation {
+	delSK, delPK, err := datagen.GenRandomBTCKeyPair(r)
+	require.NoError(t, err)
+	stakingTimeBlocks := uint16(5)
+	stakingValue := int64(2 * 10e8)
+	unbondingTime := uint16(params.MinimumUnbondingTime()) + 1
+	fpPks := testutil.GenBtcPublicKeys(r, t, fpNum)
+	testInfo := datagen.GenBTCStakingSlashingInfo(
+		r,
+		t,
+		net,
+		delSK,
+		fpPks,
+		params.CovenantPks,
+		params.CovenantQuorum,
+		stakingTimeBlocks,
+		stakingValue,
+		params.SlashingAddress.String(),
+		params.SlashingRate,
+		unbondingTime,
+	)
+	stakingTxBytes, err := bbntypes.SerializeBTCTx(testInfo.StakingTx)
+	require.NoError(t, err)
+
+	stakingTxHash := testInfo.StakingTx.TxHash()
+	testUnbondingInfo := datagen.GenBTCUnbondingSlashingInfo(
+		r,
+		t,
+		net,
+		delSK,
+		fpPks,
+		params.CovenantPks,
+		params.CovenantQuorum,
+		wire.NewOutPoint(&stakingTxHash, 0),
+		unbondingTime,
+		stakingValue-1000,
+		params.SlashingAddress.String(),
+		params.SlashingRate,
+		unbondingTime,
+	)
+	serializedUnbondingTx, err := bbntypes.SerializeBTCTx(testUnbondingInfo.UnbondingTx)
+	require.NoError(t, err)
+
+	slashingSpendInfo, err := testInfo.StakingInfo.SlashingPathSpendInfo()
+	require.NoError(t, err)
+
+	startHeight := datagen.RandomInt(r, 1000) + 100
+	return &testDelegation{
+		del: &types.Delegation{
+			BtcPk:            delPK,
+			FpBtcPks:         fpPks,
+			StartHeight:      startHeight,
+			EndHeight:        startHeight + uint64(stakingTimeBlocks),
+			TotalSat:         uint64(stakingValue),
+			UnbondingTime:    uint32(unbondingTime),
+			StakingTxHex:     hex.EncodeToString(stakingTxBytes),
+			StakingOutputIdx: 0,
+			SlashingTxHex:    testInfo.SlashingTx.ToHexStr(),
+			BtcUndelegation: &types.Undelegation{
+				UnbondingTxHex: hex.EncodeToString(serializedUnbondingTx),
+				SlashingTxHex:  testUnbondingInfo.SlashingTx.ToHexStr(),
+			},
+		},
+		stakingInfo:     testInfo,
+		stakingTxMsg:    testInfo.StakingTx,
+		unbondingTxMsg:  testUnbondingInfo.UnbondingTx,
+		stakingSlashing: slashingSpendInfo,
+	}
+}
+
+// newTestEmulator creates a covenant emulator with a new covenant key in a
+// temporary keyring and the given client controller
+func newTestEmulator(t testing.TB, cc clientcontroller.ClientController) *covenant.CovenantEmulator {
+	covenantConfig := covcfg.DefaultConfig()
+	return newTestEmulatorWithConfig(t, cc, &covenantConfig)
+}
+
+// newTestEmulatorWithConfig is newTestEmulator with the given config
+func newTestEmulatorWithConfig(
+	t testing.TB,
+	cc clientcontroller.ClientController,
+	covenantConfig *covcfg.Config,
+) *covenant.CovenantEmulator {
+	covenantConfig.BabylonConfig.KeyDirectory = t.TempDir()
+	_, err := covenant.CreateCovenantKey(
+		covenantConfig.BabylonConfig.KeyDirectory,
+		covenantConfig.BabylonConfig.ChainID,
+		covenantConfig.BabylonConfig.Key,
+		covenantConfig.BabylonConfig.KeyringBackend,
+		passphrase,
+		hdPath,
+	)
+	require.NoError(t, err)
+
+	ce, err := covenant.NewCovenantEmulator(covenantConfig, cc, passphrase, zap.NewNop())
+	require.NoError(t, err)
+	err = ce.UpdateParams()
+	require.NoError(t, err)
+
+	return ce
+}
+
+func TestAddCovenantSigInvalidOutputIdx(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	mockClientController := testutil.PrepareMockedClientController(t, params)
+	ce := newTestEmulator(t, mockClientController)
+
+	t.Run("staking output index out of range", func(t *testing.T) {
+		td := genTestDelegation(t, r, params, 2)
+		td.del.StakingOutputIdx = uint32(len(td.stakingTxMsg.TxOut))
+
+		_, err := ce.AddCovenantSignatures([]*types.Delegation{td.del})
+		require.ErrorIs(t, err, covenant.ErrInvalidOutputIdx)
+	})
+
+	t.Run("unbonding tx without outputs", func(t *testing.T) {
+		td := genTestDelegation(t, r, params, 2)
+		unbondingTx := td.unbondingTxMsg.Copy()
+		unbondingTx.TxOut = nil
+		unbondingTxBytes, err := bbntypes.SerializeBTCTx(unbondingTx)
+		require.NoError(t, err)
+		td.del.BtcUndelegation.UnbondingTxHex = hex.EncodeToString(unbondingTxBytes)
+
+		_, err = ce.AddCovenantSignatures([]*types.Delegation{td.del})
+		require.ErrorIs(t, err, covenant.ErrInvalidOutputIdx)
+	})
+}
+
+func TestAddCovenantSigInvalidUnbondingValue(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	mockClientController := testutil.PrepareMockedClientController(t, params)
+	ce := newTestEmulator(t, mockClientController)
+
+	for _, tc := range []struct {
+		name  string
+		value func(td *testDelegation) int64
+	}{
+		{"zero value", func(td *testDelegation) int64 { return 0 }},
+		{"negative value", func(td *testDelegation) int64 { return -1 }},
+		{"exceeding the staked amount", func(td *testDelegation) int64 { return int64(td.del.TotalSat) + 1 }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			td := genTestDelegation(t, r, params, 2)
+			unbondingTx := td.unbondingTxMsg.Copy()
+			unbondingTx.TxOut[0].Value = tc.value(td)
+			unbondingTxBytes, err := bbntypes.SerializeBTCTx(unbondingTx)
+			require.NoError(t, err)
+			td.del.BtcUndelegation.UnbondingTxHex = hex.EncodeToString(unbondingTxBytes)
+
+			_, err = ce.AddCovenantSignatures([]*types.Delegation{td.del})
+			require.ErrorIs(t, err, covenant.ErrInvalidUnbondingValue)
+		})
+	}
+}
+
+func TestDuplicateCovenantPks(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	mockClientController := testutil.PrepareMockedClientController(t, params)
+	ce := newTestEmulator(t, mockClientController)
+	// the delegation is generated before the committee gets duplicates
+	// as the staking scripts cannot be built with them
+	td := genTestDelegation(t, r, params, 2)
+
+	// the mocked client returns the same params which are now modified
+	params.CovenantPks = append(params.CovenantPks, ce.PublicKey(), ce.PublicKey())
+	require.NoError(t, ce.UpdateParams())
+
+	t.Run("our pk counts once as a committee member", func(t *testing.T) {
+		require.NoError(t, ce.CheckCommitteeViability())
+
+		quorum := params.CovenantQuorum
+		defer func() { params.CovenantQuorum = quorum }()
+		params.CovenantQuorum = uint32(len(params.CovenantPks))
+		require.Error(t, ce.CheckCommitteeViability())
+	})
+
+	t.Run("signing fails with a typed error", func(t *testing.T) {
+		_, err := ce.AddCovenantSignatures([]*types.Delegation{td.del})
+		require.ErrorIs(t, err, covenant.ErrDuplicateCovenantPks)
+	})
+}
+
+func TestMetricsSnapshot(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	fc := fakeclient.New(params)
+	ce := newTestEmulator(t, fc)
+	require.Zero(t, ce.Metrics())
+
+	dels := []*types.Delegation{
+		genTestDelegation(t, r, params, 2).del,
+		genTestDelegation(t, r, params, 3).del,
+	}
+	require.NoError(t, fc.AddPendingDelegations(dels...))
+
+	// the failure of the submission is counted for every delegation of the batch
+	submitErr := fmt.Errorf("submission failed")
+	fc.InjectSubmitErrors(submitErr)
+	_, err := ce.AddCovenantSignatures(dels)
+	require.ErrorIs(t, err, submitErr)
+
+	snapshot := ce.Metrics()
+	require.Zero(t, snapshot.Signed)
+	require.Equal(t, uint64(len(dels)), snapshot.Failed)
+	require.Zero(t, snapshot.InFlight)
+	require.Equal(t, submitErr.Error(), snapshot.LastError)
+
+	_, err = ce.AddCovenantSignatures(dels)
+	require.NoError(t, err)
+
+	snapshot = ce.Metrics()
+	require.Equal(t, uint64(len(dels)), snapshot.Signed)
+	require.Equal(t, uint64(len(dels)), snapshot.Failed)
+	require.Zero(t, snapshot.Skipped)
+	require.Zero(t, snapshot.InFlight)
+}
+
+func TestAddCovenantSigVanishedDelegation(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	fc := fakeclient.New(params)
+	ce := newTestEmulator(t, fc)
+
+	vanished := genTestDelegation(t, r, params, 2)
+	remaining := genTestDelegation(t, r, params, 2)
+	require.NoError(t, fc.AddPendingDelegations(vanished.del, remaining.del))
+	dels, err := fc.QueryPendingDelegations(context.Background(), 10)
+	require.NoError(t, err)
+
+	// the delegation is withdrawn after being queried but before its sigs are submitted
+	fc.RemoveDelegation(vanished.stakingTxMsg.TxHash())
+
+	res, err := ce.AddCovenantSignatures(dels)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+
+	submitted := fc.SubmittedSigs()
+	require.Len(t, submitted, 1)
+	require.Len(t, submitted[0], 1)
+	require.Equal(t, remaining.stakingTxMsg.TxHash(), submitted[0][0].StakingTxHash)
+
+	snapshot := ce.Metrics()
+	require.Equal(t, uint64(1), snapshot.Signed)
+	require.Equal(t, uint64(1), snapshot.Skipped)
+	require.Equal(t, uint64(1), snapshot.Vanished)
+	require.Zero(t, snapshot.Failed)
+}
+
+func TestAddCovenantSigDelegationFilter(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	fc := fakeclient.New(params)
+	ce := newTestEmulator(t, fc)
+
+	rejected := genTestDelegation(t, r, params, 2)
+	accepted := genTestDelegation(t, r, params, 2)
+	require.NoError(t, fc.AddPendingDelegations(rejected.del, accepted.del))
+	ce.RegisterDelegationFilter(covenant.DelegationFilterFunc(
+		func(btcDel *types.Delegation, _ *types.StakingParams) (bool, string) {
+			if btcDel.StakingTxHex == rejected.del.StakingTxHex {
+				return false, "rejected by the test filter"
+			}
+			return true, ""
+		},
+	))
+
+	res, err := ce.AddCovenantSignatures([]*types.Delegation{rejected.del, accepted.del})
+	require.NoError(t, err)
+	require.NotNil(t, res)
+
+	submitted := fc.SubmittedSigs()
+	require.Len(t, submitted, 1)
+	require.Len(t, submitted[0], 1)
+	require.Equal(t, accepted.stakingTxMsg.TxHash(), submitted[0][0].StakingTxHash)
+
+	snapshot := ce.Metrics()
+	require.Equal(t, uint64(1), snapshot.Signed)
+	require.Equal(t, uint64(1), snapshot.Skipped)
+}
+
+// malformedFpPkDeriver fails to derive the encryption key of the malformed
+// finality provider pk as the default derivation does for such a pk
+type malformedFpPkDeriver struct {
+	malformedPk []byte
+	calls       int
+}
+
+func (d *malformedFpPkDeriver) DeriveEncKey(fpPk *btcec.PublicKey) (*asig.EncryptionKey, error) {
+	d.calls++
+	if bytes.Equal(schnorr.SerializePubKey(fpPk), d.malformedPk) {
+		return nil, fmt.Errorf("malformed finality provider pk")
+	}
+	return covenant.DefaultEncKeyDeriver.DeriveEncKey(fpPk)
+}
+
+func (d *malformedFpPkDeriver) VerifyEncKey(fpPk *btcec.PublicKey, encKey *asig.EncryptionKey) error {
+	return covenant.DefaultEncKeyDeriver.VerifyEncKey(fpPk, encKey)
+}
+
+func TestAddCovenantSigMalformedFpPk(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	mockClientController := testutil.PrepareMockedClientController(t, params)
+	ce := newTestEmulator(t, mockClientController)
+
+	td := genTestDelegation(t, r, params, 3)
+	deriver := &malformedFpPkDeriver{malformedPk: schnorr.SerializePubKey(td.del.FpBtcPks[1])}
+	ce.SetEncKeyDeriver(deriver)
+
+	// the delegation is skipped rather than failing the batch
+	res, err := ce.AddCovenantSignatures([]*types.Delegation{td.del})
+	require.NoError(t, err)
+	require.Nil(t, res)
+	require.Equal(t, uint64(1), ce.Metrics().Skipped)
+	require.Zero(t, ce.Metrics().Failed)
+
+	// the failure is permanent so the delegation is not retried
+	calls := deriver.calls
+	res, err = ce.AddCovenantSignatures([]*types.Delegation{td.del})
+	require.NoError(t, err)
+	require.Nil(t, res)
+	require.Equal(t, calls, deriver.calls)
+	require.Equal(t, uint64(2), ce.Metrics().Skipped)
+}
+
+func TestListCovenantKeys(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	mockClientController := testutil.PrepareMockedClientController(t, params)
+	covenantConfig := covcfg.DefaultConfig()
+	ce := newTestEmulatorWithConfig(t, mockClientController, &covenantConfig)
+
+	keys, err := ce.ListCovenantKeys()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, covenantConfig.BabylonConfig.Key, keys[0].Name)
+	require.Equal(t, schnorr.SerializePubKey(ce.PublicKey()), schnorr.SerializePubKey(keys[0].PublicKey))
+	require.Nil(t, keys[0].PrivateKey)
+}
+
+func TestSigningTestVectors(t *testing.T) {
+	for _, v := range testutil.SigningTestVectors(t) {
+		v := v
+		t.Run(v.Name, func(t *testing.T) {
+			mockClientController := testutil.PrepareMockedClientController(t, v.Params)
+			covenantConfig := covcfg.DefaultConfig()
+			covenantConfig.BabylonConfig.KeyDirectory = t.TempDir()
+
+			// import the fixed covenant key of the vector
+			encPassphrase := "encpass"
+			armor := sdkcrypto.EncryptArmorPrivKey(&sdksecp256k1.PrivKey{Key: v.CovenantSk.Serialize()}, encPassphrase, "secp256k1")
+			armorPath := filepath.Join(t.TempDir(), "covenant-key.armor")
+			require.NoError(t, os.WriteFile(armorPath, []byte(armor), 0600))
+			_, err := covenant.ImportCovenantKey(
+				covenantConfig.BabylonConfig.KeyDirectory,
+				covenantConfig.BabylonConfig.ChainID,
+				covenantConfig.BabylonConfig.Key,
+				covenantConfig.BabylonConfig.KeyringBackend,
+				passphrase,
+				armorPath,
+				encPassphrase,
+				v.CovenantSk.PubKey(),
+			)
+			require.NoError(t, err)
+
+			ce, err := covenant.NewCovenantEmulator(&covenantConfig, mockClientController, passphrase, zap.NewNop())
+			require.NoError(t, err)
+
+			dump, err := ce.DumpSigsForDelegation(v.Delegation)
+			require.NoError(t, err)
+			require.Len(t, dump.SlashingSigs, len(v.ExpectedSlashingSigs))
+			for i, sig := range v.ExpectedSlashingSigs {
+				require.Equal(t, hex.EncodeToString(sig), dump.SlashingSigs[i].Hex)
+			}
+			require.Equal(t, hex.EncodeToString(v.ExpectedUnbondingSig), dump.UnbondingSig.Hex)
+			require.Len(t, dump.SlashingUnbondingSigs, len(v.ExpectedSlashingUnbondingSigs))
+			for i, sig := range v.ExpectedSlashingUnbondingSigs {
+				require.Equal(t, hex.EncodeToString(sig), dump.SlashingUnbondingSigs[i].Hex)
+			}
+
+			// the expected sigs verify as sigs of the covenant pk, each only as its own type
+			covPk := v.CovenantSk.PubKey()
+			for _, sig := range v.ExpectedSlashingSigs {
+				require.NoError(t, ce.VerifyCovenantSig(v.Delegation, covPk, sig, covenant.SigTypeStakingSlashing))
+			}
+			require.NoError(t, ce.VerifyCovenantSig(v.Delegation, covPk, v.ExpectedUnbondingSig, covenant.SigTypeUnbonding))
+			for _, sig := range v.ExpectedSlashingUnbondingSigs {
+				require.NoError(t, ce.VerifyCovenantSig(v.Delegation, covPk, sig, covenant.SigTypeUnbondingSlashing))
+			}
+			err = ce.VerifyCovenantSig(v.Delegation, covPk, v.ExpectedSlashingUnbondingSigs[0], covenant.SigTypeStakingSlashing)
+			require.ErrorIs(t, err, covenant.ErrInvalidCovenantSig)
+		})
+	}
+}
+
+func TestValidateDelegationStructure(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+
+	td := genTestDelegation(t, r, params, 2)
+	require.NoError(t, covenant.ValidateDelegationStructure(td.del))
+
+	t.Run("empty undelegation", func(t *testing.T) {
+		del := *genTestDelegation(t, r, params, 1).del
+		del.BtcUndelegation = nil
+		require.ErrorIs(t, covenant.ValidateDelegationStructure(&del), covenant.ErrMalformedDelegation)
+	})
+
+	t.Run("no finality providers", func(t *testing.T) {
+		del := *genTestDelegation(t, r, params, 1).del
+		del.FpBtcPks = nil
+		require.ErrorIs(t, covenant.ValidateDelegationStructure(&del), covenant.ErrMalformedDelegation)
+	})
+
+	t.Run("staking output index out of range", func(t *testing.T) {
+		td := genTestDelegation(t, r, params, 1)
+		td.del.StakingOutputIdx = uint32(len(td.stakingTxMsg.TxOut))
+		err := covenant.ValidateDelegationStructure(td.del)
+		require.ErrorIs(t, err, covenant.ErrMalformedDelegation)
+		require.ErrorIs(t, err, covenant.ErrInvalidOutputIdx)
+	})
+
+	t.Run("undecodable unbonding tx", func(t *testing.T) {
+		td := genTestDelegation(t, r, params, 1)
+		td.del.BtcUndelegation.UnbondingTxHex = "zz"
+		require.ErrorIs(t, covenant.ValidateDelegationStructure(td.del), covenant.ErrMalformedDelegation)
+	})
+}
+
+func TestProcessingWindow(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2024, 1, 1, hour, min, 0, 0, time.UTC)
+	}
+
+	w, err := covcfg.ParseProcessingWindow("01:30-05:00")
+	require.NoError(t, err)
+	require.True(t, w.Contains(at(1, 30)))
+	require.True(t, w.Contains(at(4, 59)))
+	require.False(t, w.Contains(at(5, 0)))
+	require.False(t, w.Contains(at(23, 0)))
+
+	// a window wrapping midnight
+	w, err = covcfg.ParseProcessingWindow("22:00-02:00")
+	require.NoError(t, err)
+	require.True(t, w.Contains(at(23, 0)))
+	require.True(t, w.Contains(at(1, 0)))
+	require.False(t, w.Contains(at(12, 0)))
+
+	for _, invalid := range []string{"22:00", "25:00-02:00", "03:00-03:00"} {
+		_, err := covcfg.ParseProcessingWindow(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestExportImportState(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	fc := fakeclient.New(params)
+	ce := newTestEmulator(t, fc)
+
+	// a failed submission leaves a backoff entry behind
+	td := genTestDelegation(t, r, params, 1)
+	require.NoError(t, fc.AddPendingDelegations(td.del))
+	fc.InjectSubmitErrors(fmt.Errorf("submission failed"))
+	_, err := ce.AddCovenantSignatures([]*types.Delegation{td.del})
+	require.Error(t, err)
+
+	state, err := ce.ExportState()
+	require.NoError(t, err)
+	require.Contains(t, string(state), td.stakingTxMsg.TxHash().String())
+
+	migrated := newTestEmulator(t, fc)
+	require.NoError(t, migrated.ImportState(state))
+	reexported, err := migrated.ExportState()
+	require.NoError(t, err)
+	require.JSONEq(t, string(state), string(reexported))
+
+	require.Error(t, migrated.ImportState([]byte(`{"version":1000}`)))
+}
+
+// TestConcurrentSubmissions is meant to be run with -race
+func TestConcurrentSubmissions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	fc := fakeclient.New(params)
+	fc.SetSubmitDelay(10 * time.Millisecond)
+	covenantConfig := covcfg.DefaultConfig()
+	covenantConfig.MaxConcurrentSigning = 2
+	covenantConfig.MaxConcurrentSubmissions = 4
+	covenantConfig.MaxClientFailures = 1
+	ce := newTestEmulatorWithConfig(t, fc, &covenantConfig)
+
+	batches := make([][]*types.Delegation, 0, 8)
+	for i := 0; i < 8; i++ {
+		td := genTestDelegation(t, r, params, 1)
+		require.NoError(t, fc.AddPendingDelegations(td.del))
+		batches = append(batches, []*types.Delegation{td.del})
+	}
+	// each failure reaches the limit of consecutive client failures
+	fc.InjectSubmitErrors(fmt.Errorf("submission failed"), fmt.Errorf("submission failed"))
+
+	errs := ce.SubmitBatches(batches)
+	require.Len(t, errs, len(batches))
+	var numFailed int
+	for _, err := range errs {
+		if err != nil {
+			numFailed++
+		}
+	}
+	require.Equal(t, 2, numFailed)
+	require.Len(t, fc.SubmittedSigs(), len(batches)-numFailed)
+
+	// the txs are broadcast one at a time, and the client is only
+	// reconnected after all of them are done
+	require.Equal(t, 2, fc.Reconnects())
+	require.Zero(t, fc.Overlaps())
+}
+
+// TestStuckSubmissionOffPage checks that the submission of a delegation which
+// is not on the current page of pending delegations is only forgotten once the
+// delegation no longer needs our sigs
+func TestStuckSubmissionOffPage(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	fc := fakeclient.New(params)
+	fc.SetStuckSubmissions(true)
+	covenantConfig := covcfg.DefaultConfig()
+	covenantConfig.StuckSubmissionTimeout = time.Minute
+	ce := newTestEmulatorWithConfig(t, fc, &covenantConfig)
+
+	submitted := genTestDelegation(t, r, params, 1)
+	other := genTestDelegation(t, r, params, 1)
+	require.NoError(t, fc.AddPendingDelegations(submitted.del, other.del))
+	_, err := ce.AddCovenantSignatures([]*types.Delegation{submitted.del})
+	require.NoError(t, err)
+
+	// the submission is kept while its delegation is pending on another page
+	ce.ForgetSettledSubmissions([]*types.Delegation{other.del})
+	require.Empty(t, ce.RemoveStuckSubmissions([]*types.Delegation{submitted.del}))
+
+	// the submission is forgotten once its delegation is withdrawn
+	fc.RemoveDelegation(submitted.stakingTxMsg.TxHash())
+	ce.ForgetSettledSubmissions([]*types.Delegation{other.del})
+	require.Len(t, ce.RemoveStuckSubmissions([]*types.Delegation{submitted.del}), 1)
+}
+
+func TestStuckSubmissionMaxAge(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	fc := fakeclient.New(params)
+	fc.SetStuckSubmissions(true)
+	covenantConfig := covcfg.DefaultConfig()
+	covenantConfig.StuckSubmissionTimeout = 10 * time.Millisecond
+	covenantConfig.StuckSubmissionMaxAge = 50 * time.Millisecond
+	ce := newTestEmulatorWithConfig(t, fc, &covenantConfig)
+
+	td := genTestDelegation(t, r, params, 1)
+	require.NoError(t, fc.AddPendingDelegations(td.del))
+	dels := []*types.Delegation{td.del}
+	_, err := ce.AddCovenantSignatures(dels)
+	require.NoError(t, err)
+
+	// the stuck submission is not submitted again before the max age
+	time.Sleep(covenantConfig.StuckSubmissionTimeout)
+	require.Empty(t, ce.RemoveStuckSubmissions(dels))
+
+	// the delegation is retried after the max age
+	time.Sleep(covenantConfig.StuckSubmissionMaxAge)
+	require.Len(t, ce.RemoveStuckSubmissions(dels), 1)
+	_, err = ce.AddCovenantSignatures(dels)
+	require.NoError(t, err)
+	require.Equal(t, 2, fc.Submissions())
+
+	// the retry is tracked as a new submission
+	require.Empty(t, ce.RemoveStuckSubmissions(dels))
+}
+
+// delegationJSONLine encodes the given delegation as a line of the delegations
+// file
+func delegationJSONLine(t *testing.T, del *types.Delegation) string {
+	fpPks := make([]string, 0, len(del.FpBtcPks))
+	for _, fpPk := range del.FpBtcPks {
+		fpPks = append(fpPks, hex.EncodeToString(schnorr.SerializePubKey(fpPk)))
+	}
+	line, err := json.Marshal(types.DelegationJSON{
+		BtcPk:                  hex.EncodeToString(schnorr.SerializePubKey(del.BtcPk)),
+		FpBtcPks:               fpPks,
+		StartHeight:            del.StartHeight,
+		EndHeight:              del.EndHeight,
+		TotalSat:               del.TotalSat,
+		StakingTxHex:           del.StakingTxHex,
+		StakingOutputIdx:       del.StakingOutputIdx,
+		SlashingTxHex:          del.SlashingTxHex,
+		UnbondingTime:          del.UnbondingTime,
+		UnbondingTxHex:         del.BtcUndelegation.UnbondingTxHex,
+		UnbondingSlashingTxHex: del.BtcUndelegation.SlashingTxHex,
+	})
+	require.NoError(t, err)
+
+	return string(line) + "\n"
+}
+
+func TestAddCovenantSignaturesFromReaderSummary(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	fc := fakeclient.New(params)
+	ce := newTestEmulator(t, fc)
+
+	rejected := genTestDelegation(t, r, params, 2)
+	accepted := genTestDelegation(t, r, params, 2)
+	require.NoError(t, fc.AddPendingDelegations(rejected.del, accepted.del))
+	ce.RegisterDelegationFilter(covenant.DelegationFilterFunc(
+		func(btcDel *types.Delegation, _ *types.StakingParams) (bool, string) {
+			return btcDel.StakingTxHex != rejected.del.StakingTxHex, "rejected by the test filter"
+		},
+	))
+
+	input := delegationJSONLine(t, rejected.del) + "not a delegation\n" + delegationJSONLine(t, accepted.del)
+	summary, err := ce.AddCovenantSignaturesFromReader(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Equal(t, covenant.SigningSummary{
+		Total:   3,
+		Invalid: 1,
+		Signed:  1,
+		Skipped: 1,
+	}, *summary)
+
+	// a batch of which every delegation is skipped submits nothing
+	summary, err = ce.AddCovenantSignaturesFromReader(strings.NewReader(delegationJSONLine(t, rejected.del)))
+	require.NoError(t, err)
+	require.Equal(t, covenant.SigningSummary{Total: 1, Skipped: 1}, *summary)
+}
+
+func TestAddCovenantSignaturesFromReaderStreams(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	fc := fakeclient.New(params)
+	cfg := covcfg.DefaultConfig()
+	cfg.SigsBatchSize = 1
+	ce := newTestEmulatorWithConfig(t, fc, &cfg)
+
+	first := genTestDelegation(t, r, params, 2)
+	second := genTestDelegation(t, r, params, 2)
+	require.NoError(t, fc.AddPendingDelegations(first.del, second.del))
+
+	// the batches read before the reader fails are already submitted
+	input := io.MultiReader(
+		strings.NewReader(delegationJSONLine(t, first.del)+delegationJSONLine(t, second.del)),
+		iotest.ErrReader(errors.New("connection reset")),
+	)
+	_, err := ce.AddCovenantSignaturesFromReader(input)
+	require.ErrorContains(t, err, "connection reset")
+	require.Equal(t, 2, fc.Submissions())
+}
+
+// foreignSpendPathSelector selects the staking slashing path of another
+// delegation, which is not in the script tree of the signed delegation
+type foreignSpendPathSelector struct {
+	covenant.SpendPathSelector
+	foreign *btcstaking.StakingInfo
+}
+
+func (s foreignSpendPathSelector) StakingSlashingPath(*types.Delegation, *btcstaking.StakingInfo) (*btcstaking.SpendInfo, error) {
+	return s.foreign.SlashingPathSpendInfo()
+}
+
+func TestSpendPathSelector(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	fc := fakeclient.New(params)
+	ce := newTestEmulator(t, fc)
+
+	td := genTestDelegation(t, r, params, 2)
+	other := genTestDelegation(t, r, params, 2)
+	_, err := ce.DumpSigsForDelegation(td.del)
+	require.NoError(t, err)
+
+	foreign, _, err := covenant.BuildDelegationScripts(other.del, params, net)
+	require.NoError(t, err)
+	ce.SetSpendPathSelector(foreignSpendPathSelector{
+		SpendPathSelector: covenant.DefaultSpendPathSelector,
+		foreign:           foreign,
+	})
+
+	_, err = ce.DumpSigsForDelegation(td.del)
+	require.ErrorIs(t, err, covenant.ErrSpendPathMismatch)
+	require.ErrorIs(t, err, covenant.ErrUnsignableDelegation)
+}
+
+func TestDelegationBackoff(t *testing.T) {
+	errA := fmt.Errorf("submission failed with cause a")
+	errB := fmt.Errorf("submission failed with cause b")
+
+	testCases := []struct {
+		name string
+		// results are the results of the submissions in order
+		results          []error
+		expectedInterval time.Duration
+	}{
+		{
+			name:             "first failure",
+			results:          []error{errA},
+			expectedInterval: time.Second,
+		},
+		{
+			name:             "doubled upon each failure with the same cause",
+			results:          []error{errA, errA, errA},
+			expectedInterval: 4 * time.Second,
+		},
+		{
+			name:             "capped at the max interval",
+			results:          []error{errA, errA, errA, errA, errA},
+			expectedInterval: 8 * time.Second,
+		},
+		{
+			name:             "reset when the cause changes",
+			results:          []error{errA, errA, errA, errB},
+			expectedInterval: time.Second,
+		},
+		{
+			name:             "reset upon success",
+			results:          []error{errA, errA, nil},
+			expectedInterval: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			params := testutil.GenRandomParams(r, t)
+			fc := fakeclient.New(params)
+			cfg := covcfg.DefaultConfig()
+			cfg.MinRetryInterval = time.Second
+			cfg.MaxRetryInterval = 8 * time.Second
+			ce := newTestEmulatorWithConfig(t, fc, &cfg)
+
+			td := genTestDelegation(t, r, params, 2)
+			require.NoError(t, fc.AddPendingDelegations(td.del))
+			for _, result := range tc.results {
+				if result == nil {
+					_, err := ce.AddCovenantSignatures([]*types.Delegation{td.del})
+					require.NoError(t, err)
+					continue
+				}
+				fc.InjectSubmitErrors(result)
+				_, err := ce.AddCovenantSignatures([]*types.Delegation{td.del})
+				require.ErrorIs(t, err, result)
+			}
+
+			require.Equal(t, tc.expectedInterval, ce.BackoffInterval(td.stakingTxMsg.TxHash().String()))
+		})
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	fc := fakeclient.New(params)
+	cfg := covcfg.DefaultConfig()
+	cfg.BreakerThreshold = 2
+	cfg.BreakerCooldown = time.Minute
+	ce := newTestEmulatorWithConfig(t, fc, &cfg)
+
+	td := genTestDelegation(t, r, params, 2)
+	require.NoError(t, fc.AddPendingDelegations(td.del))
+	dels := []*types.Delegation{td.del}
+	submitErr := fmt.Errorf("submission failed")
+	submitFailing := func() {
+		fc.InjectSubmitErrors(submitErr)
+		_, err := ce.AddCovenantSignatures(dels)
+		require.ErrorIs(t, err, submitErr)
+	}
+	require.Equal(t, "closed", ce.Status().BreakerState)
+
+	// the breaker opens once the failures reach the threshold
+	submitFailing()
+	require.Equal(t, "closed", ce.Status().BreakerState)
+	submitFailing()
+	require.Equal(t, "open", ce.Status().BreakerState)
+	require.False(t, ce.AllowClientCalls(time.Now()))
+
+	// it half-opens after the cooldown, and a failing probe opens it again
+	afterCooldown := time.Now().Add(cfg.BreakerCooldown)
+	require.True(t, ce.AllowClientCalls(afterCooldown))
+	require.Equal(t, "half-open", ce.Status().BreakerState)
+	submitFailing()
+	require.Equal(t, "open", ce.Status().BreakerState)
+	require.False(t, ce.AllowClientCalls(time.Now()))
+
+	// a successful probe closes it
+	afterCooldown = time.Now().Add(cfg.BreakerCooldown)
+	require.True(t, ce.AllowClientCalls(afterCooldown))
+	require.Equal(t, "half-open", ce.Status().BreakerState)
+	_, err := ce.AddCovenantSignatures(dels)
+	require.NoError(t, err)
+	require.Equal(t, "closed", ce.Status().BreakerState)
+	require.True(t, ce.AllowClientCalls(time.Now()))
+}
+
+func TestUnsignableDelegations(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	fc := fakeclient.New(params)
+	cfg := covcfg.DefaultConfig()
+	cfg.PaginateDelegations = true
+	cfg.PendingQueryRetryDelay = 0
+	ce := newTestEmulatorWithConfig(t, fc, &cfg)
+
+	td := genTestDelegation(t, r, params, 2)
+	other := genTestDelegation(t, r, params, 2)
+	require.NoError(t, fc.AddPendingDelegations(td.del, other.del))
+	hash := td.stakingTxMsg.TxHash().String()
+	dels := []*types.Delegation{td.del}
+
+	// a delegation failing permanently is inserted
+	deriver := &malformedFpPkDeriver{malformedPk: schnorr.SerializePubKey(td.del.FpBtcPks[0])}
+	ce.SetEncKeyDeriver(deriver)
+	res, err := ce.AddCovenantSignatures(dels)
+	require.NoError(t, err)
+	require.Nil(t, res)
+	require.True(t, ce.IsUnsignable(hash))
+
+	// it is kept while pending, also on rounds querying another page, during
+	// which it is skipped without signing
+	for _, page := range [][]*types.Delegation{{td.del}, {other.del}} {
+		pending, err := ce.QueryPendingWithRetry(1)
+		require.NoError(t, err)
+		require.Equal(t, page, pending)
+		require.True(t, ce.IsUnsignable(hash))
+	}
+	ce.SetEncKeyDeriver(covenant.DefaultEncKeyDeriver)
+	res, err = ce.AddCovenantSignatures(dels)
+	require.NoError(t, err)
+	require.Nil(t, res)
+	require.Empty(t, fc.SubmittedSigs())
+
+	// it expires once every page is seen without it, i.e., it is no longer
+	// pending, after which it is admitted again
+	fc.RemoveDelegation(td.stakingTxMsg.TxHash())
+	pending, err := ce.QueryPendingWithRetry(1)
+	require.NoError(t, err)
+	require.Equal(t, []*types.Delegation{other.del}, pending)
+	require.False(t, ce.IsUnsignable(hash))
+	require.NoError(t, fc.AddPendingDelegations(td.del))
+	res, err = ce.AddCovenantSignatures(dels)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	require.Len(t, fc.SubmittedSigs(), 1)
+}
+
+func TestDelegationCursor(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	fc := fakeclient.New(params)
+	pending := make([]*types.Delegation, 0, 5)
+	for i := 0; i < 5; i++ {
+		pending = append(pending, genTestDelegation(t, r, params, 1).del)
+	}
+	require.NoError(t, fc.AddPendingDelegations(pending...))
+
+	cursorFile := filepath.Join(t.TempDir(), "cursor")
+	newEmulator := func() *covenant.CovenantEmulator {
+		cfg := covcfg.DefaultConfig()
+		cfg.PaginateDelegations = true
+		cfg.DelegationCursorFile = cursorFile
+		cfg.PendingQueryRetryDelay = 0
+		return newTestEmulatorWithConfig(t, fc, &cfg)
+	}
+	// requireRound checks that the next round queries the given page
+	requireRound := func(ce *covenant.CovenantEmulator, expected []*types.Delegation) {
+		dels, err := ce.QueryPendingWithRetry(2)
+		require.NoError(t, err)
+		require.Equal(t, expected, dels)
+	}
+
+	// the cursor advances upon each round and wraps around after the last page
+	ce := newEmulator()
+	requireRound(ce, pending[0:2])
+	requireRound(ce, pending[2:4])
+	requireRound(ce, pending[4:5])
+	requireRound(ce, pending[0:2])
+
+	// the cursor is loaded by the constructor after a restart
+	ce = newEmulator()
+	requireRound(ce, pending[2:4])
+
+	// a cursor failing the query is reset to the first page
+	fc.InjectQueryErrors(fmt.Errorf("the page key is invalidated"))
+	requireRound(ce, pending[0:2])
+
+	// so is a cursor past the pending delegations, e.g., after some are activated
+	require.NoError(t, os.WriteFile(cursorFile, []byte(hex.EncodeToString([]byte{0, 0, 0, 0, 0, 0, 0, 10})), 0600))
+	ce = newEmulator()
+	requireRound(ce, pending[0:2])
+
+	// and a cursor that cannot be loaded
+	require.NoError(t, os.WriteFile(cursorFile, []byte("not a cursor"), 0600))
+	ce = newEmulator()
+	requireRound(ce, pending[0:2])
+	requireRound(ce, pending[2:4])
+}
+
+func TestConfirmSubmission(t *testing.T) {
+	pollInterval := covenant.WaitPollInterval
+	covenant.WaitPollInterval = time.Millisecond
+	t.Cleanup(func() { covenant.WaitPollInterval = pollInterval })
+
+	testCases := []struct {
+		name        string
+		mode        string
+		maxAttempts uint32
+		timeout     time.Duration
+		// stuck makes the submitted sigs never included
+		stuck bool
+		// vanished removes the delegation after the submission
+		vanished bool
+		// queryErrs fail the first polls
+		queryErrs   []error
+		expectedErr error
+		backingOff  bool
+	}{
+		{
+			name:    "confirmed",
+			mode:    covcfg.SubmissionConfirmationRequired,
+			timeout: time.Minute,
+		},
+		{
+			name:      "confirmed after failed polls",
+			mode:      covcfg.SubmissionConfirmationRequired,
+			timeout:   time.Minute,
+			queryErrs: []error{fmt.Errorf("query failed"), fmt.Errorf("query failed")},
+		},
+		{
+			name:        "unconfirmed within the max attempts",
+			mode:        covcfg.SubmissionConfirmationRequired,
+			maxAttempts: 3,
+			timeout:     time.Minute,
+			stuck:       true,
+			expectedErr: covenant.ErrSubmittedUnconfirmed,
+			backingOff:  true,
+		},
+		{
+			name:        "unconfirmed within the timeout",
+			mode:        covcfg.SubmissionConfirmationRequired,
+			timeout:     50 * time.Millisecond,
+			stuck:       true,
+			expectedErr: covenant.ErrSubmittedUnconfirmed,
+			backingOff:  true,
+		},
+		{
+			name:        "unconfirmed best effort",
+			mode:        covcfg.SubmissionConfirmationBestEffort,
+			maxAttempts: 3,
+			timeout:     time.Minute,
+			stuck:       true,
+		},
+		{
+			name:     "vanished",
+			mode:     covcfg.SubmissionConfirmationRequired,
+			timeout:  time.Minute,
+			stuck:    true,
+			vanished: true,
+		},
+		{
+			name:  "not waited for",
+			mode:  covcfg.SubmissionConfirmationNone,
+			stuck: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			params := testutil.GenRandomParams(r, t)
+			fc := fakeclient.New(params)
+			cfg := covcfg.DefaultConfig()
+			cfg.SubmissionConfirmation = tc.mode
+			cfg.ConfirmationMaxAttempts = tc.maxAttempts
+			cfg.ConfirmationTimeout = tc.timeout
+			cfg.MinRetryInterval = time.Second
+			cfg.MaxRetryInterval = time.Minute
+			ce := newTestEmulatorWithConfig(t, fc, &cfg)
+
+			td := genTestDelegation(t, r, params, 2)
+			require.NoError(t, fc.AddPendingDelegations(td.del))
+			dels := []*types.Delegation{td.del}
+			fc.SetStuckSubmissions(tc.stuck)
+			_, err := ce.AddCovenantSignatures(dels)
+			require.NoError(t, err)
+			if tc.vanished {
+				fc.RemoveDelegation(td.stakingTxMsg.TxHash())
+			}
+			fc.InjectQueryErrors(tc.queryErrs...)
+
+			err = ce.ConfirmSubmission([]chainhash.Hash{td.stakingTxMsg.TxHash()})
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			backoffInterval := ce.BackoffInterval(td.stakingTxMsg.TxHash().String())
+			require.Equal(t, tc.backingOff, backoffInterval != 0)
+			if tc.vanished {
+				require.Equal(t, uint64(1), ce.Metrics().Vanished)
+			}
+		})
+	}
+}
+
+// TestConfirmOnlySubmitted checks that only the delegations of which the sigs
+// are submitted are waited for, not those of the batch skipped in signing
+func TestConfirmOnlySubmitted(t *testing.T) {
+	pollInterval := covenant.WaitPollInterval
+	covenant.WaitPollInterval = time.Millisecond
+	t.Cleanup(func() { covenant.WaitPollInterval = pollInterval })
+
+	r := rand.New(rand.NewSource(1))
+	params := testutil.GenRandomParams(r, t)
+	fc := fakeclient.New(params)
+	cfg := covcfg.DefaultConfig()
+	cfg.SubmissionConfirmation = covcfg.SubmissionConfirmationRequired
+	cfg.ConfirmationMaxAttempts = 3
+	cfg.ConfirmationTimeout = time.Minute
+	cfg.MinRetryInterval = time.Second
+	cfg.MaxRetryInterval = time.Minute
+	ce := newTestEmulatorWithConfig(t, fc, &cfg)
+
+	unsignable := genTestDelegation(t, r, params, 1)
+	signable := genTestDelegation(t, r, params, 1)
+	require.NoError(t, fc.AddPendingDelegations(unsignable.del, signable.del))
+	ce.SetEncKeyDeriver(&malformedFpPkDeriver{malformedPk: schnorr.SerializePubKey(unsignable.del.FpBtcPks[0])})
+
+	errs := ce.SubmitBatches([][]*types.Delegation{{unsignable.del, signable.del}})
+	require.Len(t, errs, 1)
+	require.NoError(t, errs[0])
+	require.Len(t, fc.SubmittedSigs(), 1)
+	require.Zero(t, ce.BackoffInterval(unsignable.stakingTxMsg.TxHash().String()))
+	require.Zero(t, ce.BackoffInterval(signable.stakingTxMsg.TxHash().String()))
+}
+
+func TestQueryPendingWithRetry(t *testing.T) {
+	queryErr := fmt.Errorf("query failed")
+
+	testCases := []struct {
+		name     string
+		attempts uint32
+		timeout  time.Duration
+		// delay is the time each query takes
+		delay           time.Duration
+		queryErrs       []error
+		expectedErr     error
+		expectedQueries int
+	}{
+		{
+			name:            "succeeds at once",
+			attempts:        3,
+			expectedQueries: 1,
+		},
+		{
+			name:            "succeeds after retries",
+			attempts:        3,
+			queryErrs:       []error{queryErr, queryErr},
+			expectedQueries: 3,
+		},
+		{
+			name:            "fails after the attempts",
+			attempts:        3,
+			queryErrs:       []error{queryErr, queryErr, queryErr},
+			expectedErr:     queryErr,
+			expectedQueries: 3,
+		},
+		{
+			name:            "no retry",
+			attempts:        0,
+			queryErrs:       []error{queryErr},
+			expectedErr:     queryErr,
+			expectedQueries: 1,
+		},
+		{
+			name:            "times out",
+			attempts:        2,
+			timeout:         10 * time.Millisecond,
+			delay:           time.Second,
+			expectedErr:     covenant.ErrQueryTimeout,
+			expectedQueries: 2,
+		},
+		{
+			name:            "completes within the timeout",
+			attempts:        2,
+			timeout:         time.Second,
+			delay:           10 * time.Millisecond,
+			expectedQueries: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			params := testutil.GenRandomParams(r, t)
+			fc := fakeclient.New(params)
+			cfg := covcfg.DefaultConfig()
+			cfg.PendingQueryAttempts = tc.attempts
+			cfg.PendingQueryRetryDelay = time.Millisecond
+			cfg.PendingQueryTimeout = tc.timeout
+			ce := newTestEmulatorWithConfig(t, fc, &cfg)
+
+			td := genTestDelegation(t, r, params, 1)
+			require.NoError(t, fc.AddPendingDelegations(td.del))
+			fc.SetQueryDelay(tc.delay)
+			fc.InjectQueryErrors(tc.queryErrs...)
+
+			dels, err := ce.QueryPendingWithRetry(10)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				require.Nil(t, dels)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, []*types.Delegation{td.del}, dels)
+			}
+			require.Equal(t, tc.expectedQueries, fc.PendingQueries())
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package covenant
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// delegationCursor is the page key of the pending delegations to query in the
+// next round. It is persisted to a file, if configured, so that the emulator
+// resumes paging where it left off after a restart.
+type delegationCursor struct {
+	mu      sync.Mutex
+	pageKey []byte
+	path    string
+	logger  *zap.Logger
+}
+
+// newDelegationCursor loads the cursor from the given file, if any. A cursor
+// that cannot be loaded is reset to the first page.
+func newDelegationCursor(path string, logger *zap.Logger) *delegationCursor {
+	c := &delegationCursor{path: path, logger: logger}
+	if path == "" {
+		return c
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c
+	}
+	if err != nil {
+		logger.Warn("failed to read the delegation cursor, starting from the first page",
+			zap.String("path", path), zap.Error(err))
+		return c
+	}
+	pageKey, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		logger.Warn("invalid delegation cursor, starting from the first page",
+			zap.String("path", path), zap.Error(err))
+		return c
+	}
+	c.pageKey = pageKey
+
+	return c
+}
+
+func (c *delegationCursor) get() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.pageKey
+}
+
+// set sets and persists the page key of the next round, where an
+// empty key means the first page
+func (c *delegationCursor) set(pageKey []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pageKey = pageKey
+	if c.path == "" {
+		return
+	}
+	if err := os.WriteFile(c.path, []byte(hex.EncodeToString(pageKey)), 0600); err != nil {
+		c.logger.Warn("failed to persist the delegation cursor",
+			zap.String("path", c.path), zap.Error(err))
+	}
+}
+
+// pendingSweep collects the staking tx hashes of the pending delegations
+// queried since the first page, so that the state kept about pending
+// delegations is pruned against the full pending set rather than a page
+type pendingSweep struct {
+	mu     sync.Mutex
+	hashes map[string]struct{}
+}
+
+// observe adds the given page of pending delegations, where first and last
+// tell whether it is the first and the last page. It returns the full pending
+// set once the last page is added to a sweep that started at the first page,
+// or nil otherwise.
+func (s *pendingSweep) observe(dels []*types.Delegation, first, last bool) map[string]struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if first {
+		s.hashes = make(map[string]struct{}, len(dels))
+	}
+	if s.hashes == nil {
+		// the emulator resumed paging in the middle of a sweep
+		return nil
+	}
+	for _, del := range dels {
+		if hash, ok := stakingTxHashOf(del); ok {
+			s.hashes[hash] = struct{}{}
+		}
+	}
+	if !last {
+		return nil
+	}
+
+	pending := s.hashes
+	s.hashes = nil
+
+	return pending
+}
+
+// queryPendingDelegations queries the pending delegations of a round. If
+// pagination is enabled, each round queries the page after the one of the
+// previous round and wraps around to the first page after the last one.
+// A cursor that is invalidated by state changes on the consumer chain, i.e.,
+// the query fails or returns no delegations, is reset to the first page.
+// Once a sweep from the first to the last page completes, the unsignable and
+// reorged delegations which it did not return are pruned.
+func (ce *CovenantEmulator) queryPendingDelegations(ctx context.Context, limit uint64) ([]*types.Delegation, error) {
+	if !ce.config.PaginateDelegations {
+		dels, err := ce.cc.QueryPendingDelegations(ctx, limit)
+		if err != nil {
+			return nil, err
+		}
+		// the delegations exceeding the limit are not returned
+		complete := limit == 0 || uint64(len(dels)) < limit
+		ce.retainPending(ce.sweep.observe(dels, true, complete))
+
+		return dels, nil
+	}
+
+	pageKey := ce.cursor.get()
+	dels, nextKey, err := ce.cc.QueryPendingDelegationsPage(ctx, limit, pageKey)
+	if len(pageKey) != 0 && (err != nil || len(dels) == 0) {
+		ce.logger.Debug("the delegation cursor is invalidated, restarting from the first page",
+			zap.String("page_key", hex.EncodeToString(pageKey)), zap.Error(err))
+		pageKey = nil
+		dels, nextKey, err = ce.cc.QueryPendingDelegationsPage(ctx, limit, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the page of pending delegations: %w", err)
+	}
+
+	ce.cursor.set(nextKey)
+	ce.retainPending(ce.sweep.observe(dels, len(pageKey) == 0, len(nextKey) == 0))
+
+	return dels, nil
+}
+
+// retainPending prunes the unsignable and reorged delegations which are not in
+// the given pending set of a full sweep, if the sweep completed
+func (ce *CovenantEmulator) retainPending(pending map[string]struct{}) {
+	if pending == nil {
+		return
+	}
+
+	ce.unsignable.retainPending(pending)
+	ce.reorged.retainPending(pending)
+}
@@ -0,0 +1,92 @@
+package covenant
+
+import (
+	"encoding/hex"
+
+	bbntypes "github.com/babylonchain/babylon/types"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// logDecodedDelegation logs all the fields of the delegation as received
+// from the consumer chain together with its decoded txs
+func logDecodedDelegation(logger *zap.Logger, btcDel *types.Delegation) {
+	if !logger.Core().Enabled(zap.DebugLevel) {
+		return
+	}
+
+	fpPks := make([]string, 0, len(btcDel.FpBtcPks))
+	for _, fpPk := range btcDel.FpBtcPks {
+		if fpPk == nil {
+			fpPks = append(fpPks, "")
+			continue
+		}
+		fpPks = append(fpPks, hex.EncodeToString(schnorr.SerializePubKey(fpPk)))
+	}
+	var btcPk string
+	if btcDel.BtcPk != nil {
+		btcPk = hex.EncodeToString(schnorr.SerializePubKey(btcDel.BtcPk))
+	}
+	var stakingTime uint64
+	if btcDel.EndHeight > btcDel.StartHeight {
+		stakingTime = btcDel.EndHeight - btcDel.StartHeight
+	}
+
+	logger.Debug("decoded delegation",
+		zap.String("btc_pk", btcPk),
+		zap.Strings("fp_btc_pks", fpPks),
+		zap.Uint64("start_height", btcDel.StartHeight),
+		zap.Uint64("end_height", btcDel.EndHeight),
+		zap.Uint64("staking_time", stakingTime),
+		zap.Uint64("total_sat", btcDel.TotalSat),
+		zap.Uint32("staking_output_idx", btcDel.StakingOutputIdx),
+		zap.Uint32("unbonding_time", btcDel.UnbondingTime),
+		zap.Int("num_covenant_sigs", len(btcDel.CovenantSigs)),
+		zap.Int("num_covenant_unbonding_sigs", len(btcDel.BtcUndelegation.CovenantUnbondingSigs)),
+		zap.Any("staking_tx", summarizeTx(btcDel.StakingTxHex)),
+		zap.Any("slashing_tx", summarizeTx(btcDel.SlashingTxHex)),
+		zap.Any("unbonding_tx", summarizeTx(btcDel.BtcUndelegation.UnbondingTxHex)),
+		zap.Any("unbonding_slashing_tx", summarizeTx(btcDel.BtcUndelegation.SlashingTxHex)),
+	)
+}
+
+// txSummary is the decoded form of a tx for debug logs
+type txSummary struct {
+	Hash     string          `json:"hash"`
+	Inputs   []string        `json:"inputs"`
+	Outputs  []outputSummary `json:"outputs"`
+	LockTime uint32          `json:"lock_time"`
+	Error    string          `json:"error,omitempty"`
+}
+
+type outputSummary struct {
+	Value    int64  `json:"value"`
+	PkScript string `json:"pk_script"`
+}
+
+func summarizeTx(txHex string) txSummary {
+	tx, _, err := bbntypes.NewBTCTxFromHex(txHex)
+	if err != nil {
+		return txSummary{Error: err.Error()}
+	}
+
+	summary := txSummary{
+		Hash:     tx.TxHash().String(),
+		Inputs:   make([]string, 0, len(tx.TxIn)),
+		Outputs:  make([]outputSummary, 0, len(tx.TxOut)),
+		LockTime: tx.LockTime,
+	}
+	for _, in := range tx.TxIn {
+		summary.Inputs = append(summary.Inputs, in.PreviousOutPoint.String())
+	}
+	for _, out := range tx.TxOut {
+		summary.Outputs = append(summary.Outputs, outputSummary{
+			Value:    out.Value,
+			PkScript: hex.EncodeToString(out.PkScript),
+		})
+	}
+
+	return summary
+}
@@ -0,0 +1,149 @@
+package covenant
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	bbntypes "github.com/babylonchain/babylon/types"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// EncodedSig is a signature encoded in both hex and base64
+type EncodedSig struct {
+	Hex    string `json:"hex"`
+	Base64 string `json:"base64"`
+}
+
+// SigsDump contains the covenant signatures computed for a delegation
+// together with the finality provider pks and the script paths they commit to
+type SigsDump struct {
+	StakingTxHash string   `json:"staking_tx_hash"`
+	CovenantPk    string   `json:"covenant_pk"`
+	FpBtcPks      []string `json:"fp_btc_pks"`
+
+	// the script paths are hex encoded
+	StakingSlashingPath   string `json:"staking_slashing_path"`
+	StakingUnbondingPath  string `json:"staking_unbonding_path"`
+	UnbondingSlashingPath string `json:"unbonding_slashing_path"`
+
+	// SlashingSigs and SlashingUnbondingSigs are ordered as FpBtcPks
+	SlashingSigs          []EncodedSig `json:"slashing_sigs"`
+	UnbondingSig          EncodedSig   `json:"unbonding_sig"`
+	SlashingUnbondingSigs []EncodedSig `json:"slashing_unbonding_sigs"`
+}
+
+// DumpSigsForDelegation validates the given delegation and computes the
+// covenant signatures for it without submitting them
+func (ce *CovenantEmulator) DumpSigsForDelegation(btcDel *types.Delegation) (*SigsDump, error) {
+	if err := ce.UpdateParams(); err != nil {
+		return nil, fmt.Errorf("failed to get staking params: %w", err)
+	}
+
+	return ce.dumpSigs(btcDel, ce.params.Load())
+}
+
+// SignRawDelegation constructs a delegation from the given raw txs and pks, all
+// hex encoded, and computes the covenant signatures for it against the given
+// staking params without querying the consumer chain. The staked amount is taken
+// from the staking output. It allows reproducing signing failures offline, and
+// the staking params of the emulator are left untouched.
+func (ce *CovenantEmulator) SignRawDelegation(
+	stakingTxHex, slashingTxHex, unbondingTxHex, unbondingSlashingTxHex string,
+	btcPk string, fpPks []string,
+	stakingOutputIdx uint32, stakingTime uint16, unbondingTime uint32,
+	params *types.StakingParams,
+) (*SigsDump, error) {
+	if params == nil {
+		return nil, fmt.Errorf("empty staking params")
+	}
+
+	stakingMsgTx, _, err := bbntypes.NewBTCTxFromHex(stakingTxHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid staking tx: %w", err)
+	}
+	if int(stakingOutputIdx) >= len(stakingMsgTx.TxOut) {
+		return nil, fmt.Errorf("%w: staking output index %d, the staking tx has %d outputs",
+			ErrInvalidOutputIdx, stakingOutputIdx, len(stakingMsgTx.TxOut))
+	}
+
+	delJSON := &types.DelegationJSON{
+		BtcPk:                  btcPk,
+		FpBtcPks:               fpPks,
+		EndHeight:              uint64(stakingTime),
+		TotalSat:               uint64(stakingMsgTx.TxOut[stakingOutputIdx].Value),
+		StakingTxHex:           stakingTxHex,
+		StakingOutputIdx:       stakingOutputIdx,
+		SlashingTxHex:          slashingTxHex,
+		UnbondingTime:          unbondingTime,
+		UnbondingTxHex:         unbondingTxHex,
+		UnbondingSlashingTxHex: unbondingSlashingTxHex,
+	}
+	btcDel, err := delJSON.ToDelegation()
+	if err != nil {
+		return nil, fmt.Errorf("invalid delegation: %w", err)
+	}
+
+	return ce.dumpSigs(btcDel, params)
+}
+
+// dumpSigs computes the covenant signatures for the given delegation
+// against the given staking params
+func (ce *CovenantEmulator) dumpSigs(btcDel *types.Delegation, params *types.StakingParams) (*SigsDump, error) {
+	covSigs, err := ce.signDelegation(btcDel, params, ce.logger, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	stakingInfo, unbondingInfo, err := BuildDelegationScripts(btcDel, params, ce.btcNetOf(params))
+	if err != nil {
+		return nil, err
+	}
+	stakingSlashingPath, err := stakingInfo.SlashingPathSpendInfo()
+	if err != nil {
+		return nil, err
+	}
+	stakingUnbondingPath, err := stakingInfo.UnbondingPathSpendInfo()
+	if err != nil {
+		return nil, err
+	}
+	unbondingSlashingPath, err := unbondingInfo.SlashingPathSpendInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	fpPks := make([]string, 0, len(btcDel.FpBtcPks))
+	for _, fpPk := range btcDel.FpBtcPks {
+		fpPks = append(fpPks, hex.EncodeToString(schnorr.SerializePubKey(fpPk)))
+	}
+
+	return &SigsDump{
+		StakingTxHash:         covSigs.StakingTxHash.String(),
+		CovenantPk:            hex.EncodeToString(schnorr.SerializePubKey(covSigs.PublicKey)),
+		FpBtcPks:              fpPks,
+		StakingSlashingPath:   hex.EncodeToString(stakingSlashingPath.GetPkScriptPath()),
+		StakingUnbondingPath:  hex.EncodeToString(stakingUnbondingPath.GetPkScriptPath()),
+		UnbondingSlashingPath: hex.EncodeToString(unbondingSlashingPath.GetPkScriptPath()),
+		SlashingSigs:          encodeSigs(covSigs.SlashingSigs),
+		UnbondingSig:          encodeSig(covSigs.UnbondingSig.Serialize()),
+		SlashingUnbondingSigs: encodeSigs(covSigs.SlashingUnbondingSigs),
+	}, nil
+}
+
+func encodeSig(sig []byte) EncodedSig {
+	return EncodedSig{
+		Hex:    hex.EncodeToString(sig),
+		Base64: base64.StdEncoding.EncodeToString(sig),
+	}
+}
+
+func encodeSigs(sigs [][]byte) []EncodedSig {
+	encoded := make([]EncodedSig, 0, len(sigs))
+	for _, sig := range sigs {
+		encoded = append(encoded, encodeSig(sig))
+	}
+
+	return encoded
+}
@@ -0,0 +1,48 @@
+package covenant
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	covcfg "github.com/babylonchain/covenant-emulator/config"
+)
+
+// emptyTickLog decides which rounds without pending delegations are logged so
+// that quiet chains do not flood the debug log
+type emptyTickLog struct {
+	mode     string
+	interval time.Duration
+
+	// empty is set while the rounds are without pending delegations
+	empty      bool
+	lastLogged time.Time
+	suppressed uint64
+}
+
+// observe is called upon every round with the number of pending delegations
+func (l *emptyTickLog) observe(logger *zap.Logger, numDels int, now time.Time) {
+	if numDels != 0 {
+		l.empty = false
+		l.suppressed = 0
+		return
+	}
+
+	transition := !l.empty
+	l.empty = true
+
+	switch {
+	case l.mode == covcfg.EmptyTickLogEvery || transition:
+		logger.Debug("no pending delegations are found")
+	case l.mode == covcfg.EmptyTickLogSummary && now.Sub(l.lastLogged) >= l.interval:
+		logger.Debug("no pending delegations are found in consecutive rounds",
+			zap.Uint64("rounds", l.suppressed+1),
+			zap.Duration("since", now.Sub(l.lastLogged)),
+		)
+	default:
+		l.suppressed++
+		return
+	}
+	l.lastLogged = now
+	l.suppressed = 0
+}
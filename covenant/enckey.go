@@ -0,0 +1,70 @@
+package covenant
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	asig "github.com/babylonchain/babylon/crypto/schnorr-adaptor-signature"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// EncKeyDeriver derives the encryption key of the covenant adaptor signatures
+// from the BTC public key of a finality provider
+type EncKeyDeriver interface {
+	// DeriveEncKey derives the encryption key for the given finality provider
+	DeriveEncKey(fpPk *btcec.PublicKey) (*asig.EncryptionKey, error)
+	// VerifyEncKey checks that the encryption key corresponds to the given
+	// finality provider, so that only it can decrypt the adaptor signatures
+	VerifyEncKey(fpPk *btcec.PublicKey, encKey *asig.EncryptionKey) error
+}
+
+// btcPKEncKeyDeriver uses the BTC public key of the finality provider as
+// the encryption key, as in the current protocol version
+type btcPKEncKeyDeriver struct{}
+
+// DefaultEncKeyDeriver is the encryption key derivation used unless another
+// one is set via SetEncKeyDeriver
+var DefaultEncKeyDeriver EncKeyDeriver = btcPKEncKeyDeriver{}
+
+func (btcPKEncKeyDeriver) DeriveEncKey(fpPk *btcec.PublicKey) (*asig.EncryptionKey, error) {
+	return asig.NewEncryptionKeyFromBTCPK(fpPk)
+}
+
+func (btcPKEncKeyDeriver) VerifyEncKey(fpPk *btcec.PublicKey, encKey *asig.EncryptionKey) error {
+	if encKey == nil {
+		return fmt.Errorf("%w: empty encryption key", ErrEncKeyMismatch)
+	}
+	if !bytes.Equal(schnorr.SerializePubKey(encKey.ToBTCPK()), schnorr.SerializePubKey(fpPk)) {
+		return ErrEncKeyMismatch
+	}
+
+	return nil
+}
+
+// SetEncKeyDeriver sets the encryption key derivation of the adaptor signatures.
+// It must be called before the emulator is started.
+func (ce *CovenantEmulator) SetEncKeyDeriver(deriver EncKeyDeriver) {
+	ce.encKeyDeriver = deriver
+}
+
+// deriveEncKeys derives and verifies the encryption keys of the given
+// finality providers, in the same order
+func (ce *CovenantEmulator) deriveEncKeys(fpPks []*btcec.PublicKey) ([]*asig.EncryptionKey, error) {
+	encKeys := make([]*asig.EncryptionKey, 0, len(fpPks))
+	for i, fpPk := range fpPks {
+		encKey, err := ce.encKeyDeriver.DeriveEncKey(fpPk)
+		if err != nil {
+			return nil, fmt.Errorf("%w: finality provider %d with pk %s: %w",
+				ErrEncryptionKey, i, hex.EncodeToString(schnorr.SerializePubKey(fpPk)), err)
+		}
+		if err := ce.encKeyDeriver.VerifyEncKey(fpPk, encKey); err != nil {
+			return nil, fmt.Errorf("invalid encryption key of finality provider %s: %w",
+				hex.EncodeToString(schnorr.SerializePubKey(fpPk)), err)
+		}
+		encKeys = append(encKeys, encKey)
+	}
+
+	return encKeys, nil
+}
@@ -0,0 +1,98 @@
+package covenant
+
+import "errors"
+
+var (
+	// ErrKeyringUnlock is returned when the covenant private key cannot be
+	// retrieved from the keyring. This is not recoverable by retrying as no
+	// signatures can be produced without the key.
+	ErrKeyringUnlock = errors.New("failed to unlock the covenant key from the keyring")
+
+	// ErrQuorumAlreadyReached is returned when a delegation is not signed because
+	// it already has a covenant quorum. This is an expected skip rather than a failure.
+	ErrQuorumAlreadyReached = errors.New("the delegation already has a covenant quorum")
+
+	// ErrInvalidOutputIdx is returned when a tx of a delegation does not have
+	// the output that is expected to be spent or signed
+	ErrInvalidOutputIdx = errors.New("the output index is out of range of the tx outputs")
+
+	// ErrSlashingAddressNotAllowed is returned when the slashing address in the
+	// staking params is not in the configured allowlist
+	ErrSlashingAddressNotAllowed = errors.New("the slashing address is not allowed")
+
+	// ErrEncryptionKey is returned when the encryption key of the adaptor sigs
+	// cannot be derived from the pk of a finality provider of a delegation,
+	// which is permanent for the delegation
+	ErrEncryptionKey = errors.New("failed to derive the encryption key of the finality provider")
+
+	// ErrEncKeyMismatch is returned when the encryption key derived for the adaptor
+	// signatures does not correspond to the finality provider
+	ErrEncKeyMismatch = errors.New("the encryption key does not correspond to the finality provider")
+
+	// ErrUnsignableDelegation is returned when a delegation cannot be signed by
+	// this version of the emulator, e.g., its scripts have an unexpected structure.
+	// Retrying does not help, so the delegation is skipped.
+	ErrUnsignableDelegation = errors.New("the delegation cannot be signed by this version of the covenant emulator")
+
+	// ErrShuttingDown is returned when signing is interrupted as the emulator stops
+	ErrShuttingDown = errors.New("the covenant emulator is shutting down")
+
+	// ErrParamsChanged is returned when the staking params change while signing,
+	// in which case the sigs computed against the old params are not submitted
+	ErrParamsChanged = errors.New("the staking params changed while signing")
+
+	// ErrDuplicateCovenantPks is returned when the covenant committee in the
+	// staking params contains the same pk more than once, for which the
+	// staking scripts cannot be built
+	ErrDuplicateCovenantPks = errors.New("the covenant committee contains duplicate pks")
+
+	// ErrNetworkMismatch is returned when an address of a delegation or its
+	// staking params is not on the BTC network of the consumer chain
+	ErrNetworkMismatch = errors.New("the address is not on the expected BTC network")
+
+	// ErrWithdrawalUnsupported is returned when withdrawing submitted covenant
+	// sigs, which the consumer chain does not support
+	ErrWithdrawalUnsupported = errors.New("withdrawing covenant sigs is not supported by the consumer chain")
+
+	// ErrInvalidUnbondingValue is returned when the value of the unbonding output
+	// is not positive or exceeds the staked amount of the delegation
+	ErrInvalidUnbondingValue = errors.New("the unbonding output value is invalid")
+
+	// ErrSlashingTxFeeTooHigh is returned when the fee of a slashing tx of a
+	// delegation exceeds the configured maximum
+	ErrSlashingTxFeeTooHigh = errors.New("the slashing tx fee exceeds the configured maximum")
+
+	// ErrSubmittedUnconfirmed is returned when covenant sigs are submitted but
+	// not found in the delegation on the consumer chain within the timeout
+	ErrSubmittedUnconfirmed = errors.New("the covenant sigs are submitted but not confirmed")
+
+	// ErrTxRuleViolation is returned when a tx of a delegation fails the BTC
+	// consensus or standardness checks of the stricter validation mode
+	ErrTxRuleViolation = errors.New("the tx violates BTC consensus or standardness rules")
+
+	// ErrQueryTimeout is returned when querying the consumer chain takes
+	// longer than the configured timeout
+	ErrQueryTimeout = errors.New("the query to the consumer chain timed out")
+
+	// ErrInvalidParams is returned when the staking params on the consumer
+	// chain are invalid, e.g., have a malformed slashing address, in which
+	// case no delegation can be signed until they are fixed
+	ErrInvalidParams = errors.New("the staking params are invalid")
+
+	// ErrMalformedDelegation is returned when a delegation violates the
+	// structural invariants which do not depend on the staking params
+	ErrMalformedDelegation = errors.New("the delegation is malformed")
+
+	// ErrSupersededCommittee is returned when a delegation is staked under a
+	// covenant committee other than the current one, e.g., one which included
+	// our key before a membership change, so no valid sig can be produced
+	ErrSupersededCommittee = errors.New("the delegation is staked under a superseded covenant committee")
+
+	// ErrInvalidCovenantSig is returned when a given covenant sig is not
+	// valid for a delegation under the current staking params
+	ErrInvalidCovenantSig = errors.New("the covenant signature is invalid")
+
+	// ErrSpendPathMismatch is returned when a spend path selected for signing
+	// is not in the script tree of the output it spends
+	ErrSpendPathMismatch = errors.New("the spend path is not in the script tree of the output")
+)
@@ -0,0 +1,60 @@
+package covenant
+
+import (
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// SubmitBatches exposes submitBatches to the tests
+func (ce *CovenantEmulator) SubmitBatches(batches [][]*types.Delegation) []error {
+	return ce.submitBatches(batches)
+}
+
+// RemoveStuckSubmissions exposes removeStuckSubmissions to the tests
+func (ce *CovenantEmulator) RemoveStuckSubmissions(dels []*types.Delegation) []*types.Delegation {
+	return ce.removeStuckSubmissions(dels)
+}
+
+// ForgetSettledSubmissions exposes forgetSettledSubmissions to the tests
+func (ce *CovenantEmulator) ForgetSettledSubmissions(dels []*types.Delegation) {
+	ce.forgetSettledSubmissions(dels)
+}
+
+// BackoffInterval returns the interval before the delegation with the given
+// staking tx hash is retried, which is zero if it is not backing off
+func (ce *CovenantEmulator) BackoffInterval(stakingTxHash string) time.Duration {
+	ce.backoff.mu.Lock()
+	defer ce.backoff.mu.Unlock()
+
+	entry, ok := ce.backoff.entries[stakingTxHash]
+	if !ok {
+		return 0
+	}
+
+	return entry.interval
+}
+
+// AllowClientCalls returns whether the circuit breaker lets the calls to the
+// consumer chain through at the given time
+func (ce *CovenantEmulator) AllowClientCalls(now time.Time) bool {
+	return ce.breaker.allow(now)
+}
+
+// IsUnsignable returns whether the delegation with the given staking tx hash
+// is skipped as unsignable
+func (ce *CovenantEmulator) IsUnsignable(stakingTxHash string) bool {
+	return ce.unsignable.contains(stakingTxHash)
+}
+
+// QueryPendingWithRetry exposes queryPendingWithRetry to the tests
+func (ce *CovenantEmulator) QueryPendingWithRetry(limit uint64) ([]*types.Delegation, error) {
+	return ce.queryPendingWithRetry(limit)
+}
+
+// ConfirmSubmission exposes confirmSubmission to the tests
+func (ce *CovenantEmulator) ConfirmSubmission(stakingTxHashes []chainhash.Hash) error {
+	return ce.confirmSubmission(stakingTxHashes)
+}
@@ -0,0 +1,183 @@
+package covenant
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+const (
+	// StdinPath is the special path denoting that delegations are read from stdin
+	StdinPath = "-"
+
+	// maxDelegationLineSize is the maximum size of a single JSON delegation line
+	maxDelegationLineSize = 4 * 1024 * 1024
+)
+
+// SigningSummary summarizes the result of signing a set of delegations
+type SigningSummary struct {
+	Total   int `json:"total"`
+	Invalid int `json:"invalid"`
+	Signed  int `json:"signed"`
+	// Skipped counts the delegations which are not signed without failing,
+	// e.g., filtered out or already having a covenant quorum
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+}
+
+// openDelegationsFile opens the given file, or stdin if the path is "-"
+func openDelegationsFile(path string) (io.ReadCloser, error) {
+	if path == StdinPath {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open delegations file %s: %w", path, err)
+	}
+
+	return f, nil
+}
+
+// AddCovenantSignaturesFromFile reads newline-delimited JSON delegations from the
+// given file, or from stdin if the path is "-", and submits covenant signatures
+// for them. Lines that cannot be decoded are logged and skipped.
+func (ce *CovenantEmulator) AddCovenantSignaturesFromFile(path string) (*SigningSummary, error) {
+	r, err := openDelegationsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ce.AddCovenantSignaturesFromReader(r)
+}
+
+// BuildCovenantSigsTxFromFile reads newline-delimited JSON delegations from the
+// given file, or from stdin if the path is "-", and builds the unsigned tx
+// submitting covenant signatures for them. Lines that cannot be decoded are
+// logged and skipped. As the tx covers every delegation, they are all kept in
+// memory.
+func (ce *CovenantEmulator) BuildCovenantSigsTxFromFile(path string, gasLimit uint64) ([]byte, error) {
+	r, err := openDelegationsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	if err := ce.UpdateParams(); err != nil {
+		return nil, fmt.Errorf("failed to get staking params: %w", err)
+	}
+
+	dels := make([]*types.Delegation, 0)
+	err = ce.readDelegations(r, &SigningSummary{}, ce.config.SigsBatchSize, func(delBatch []*types.Delegation) {
+		dels = append(dels, delBatch...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(dels) == 0 {
+		return nil, nil
+	}
+
+	return ce.BuildCovenantSigsTx(dels, gasLimit)
+}
+
+// AddCovenantSignaturesFromReader reads newline-delimited JSON delegations from
+// the given reader and submits covenant signatures for them. The delegations
+// are signed in batches as they are read, so the input is not kept in memory.
+//
+// The delegations are taken as given rather than queried from Babylon, so
+// their covenant sigs are those of the input, if any. The checks of whether a
+// delegation already has the covenant quorum or our sig only see those sigs,
+// and a batch submitting sigs which Babylon already has fails.
+func (ce *CovenantEmulator) AddCovenantSignaturesFromReader(r io.Reader) (*SigningSummary, error) {
+	if err := ce.UpdateParams(); err != nil {
+		return nil, fmt.Errorf("failed to get staking params: %w", err)
+	}
+
+	summary := &SigningSummary{}
+	err := ce.readDelegations(r, summary, ce.config.SigsBatchSize, func(delBatch []*types.Delegation) {
+		_, counts, err := ce.addCovenantSignatures(delBatch, ce.recordClientResult)
+		summary.Signed += counts.signed
+		summary.Skipped += counts.skipped
+		if err != nil {
+			summary.Failed += len(delBatch) - counts.signed - counts.skipped
+			ce.logger.Error(
+				"failed to submit covenant signatures for BTC delegations",
+				zap.Error(err),
+			)
+		}
+	})
+	summaryFields := []zap.Field{
+		zap.Int("total", summary.Total),
+		zap.Int("invalid", summary.Invalid),
+		zap.Int("signed", summary.Signed),
+		zap.Int("skipped", summary.Skipped),
+		zap.Int("failed", summary.Failed),
+	}
+	if err != nil {
+		// the batches read before the failure are already submitted
+		ce.logger.Error("stopped signing delegations", append(summaryFields, zap.Error(err))...)
+		return nil, err
+	}
+
+	ce.logger.Info("finished signing delegations", summaryFields...)
+
+	return summary, nil
+}
+
+// readDelegations decodes the newline-delimited JSON delegations of the given
+// reader, counting the total and the invalid ones in the summary, and passes
+// them to handleBatch in batches of up to batchSize as they are decoded
+func (ce *CovenantEmulator) readDelegations(
+	r io.Reader,
+	summary *SigningSummary,
+	batchSize uint64,
+	handleBatch func(delBatch []*types.Delegation),
+) error {
+	delBatch := make([]*types.Delegation, 0, batchSize)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxDelegationLineSize)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		summary.Total++
+
+		var delJSON types.DelegationJSON
+		if err := json.Unmarshal(line, &delJSON); err != nil {
+			summary.Invalid++
+			ce.logger.Warn("skipping undecodable delegation", zap.Int("line", lineNum), zap.Error(err))
+			continue
+		}
+		del, err := delJSON.ToDelegation()
+		if err != nil {
+			summary.Invalid++
+			ce.logger.Warn("skipping invalid delegation", zap.Int("line", lineNum), zap.Error(err))
+			continue
+		}
+		delBatch = append(delBatch, del)
+		if uint64(len(delBatch)) == batchSize {
+			handleBatch(delBatch)
+			delBatch = make([]*types.Delegation, 0, batchSize)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read delegations: %w", err)
+	}
+	if len(delBatch) != 0 {
+		handleBatch(delBatch)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package covenant
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// DelegationFilter decides whether a pending delegation is signed by this
+// covenant, on top of the checks of the staking protocol, so that operators
+// can apply their own signing policies
+type DelegationFilter interface {
+	// Accept returns whether the delegation is signed under the given staking
+	// params and, if not, the reason it is skipped
+	Accept(btcDel *types.Delegation, params *types.StakingParams) (bool, string)
+}
+
+// DelegationFilterFunc adapts a function to a DelegationFilter
+type DelegationFilterFunc func(btcDel *types.Delegation, params *types.StakingParams) (bool, string)
+
+// Accept calls f
+func (f DelegationFilterFunc) Accept(btcDel *types.Delegation, params *types.StakingParams) (bool, string) {
+	return f(btcDel, params)
+}
+
+// StakingTimeFilter accepts the delegations of which the staking time is
+// within the bounds. A zero bound means no limit on that side.
+type StakingTimeFilter struct {
+	Min uint16
+	Max uint16
+}
+
+// Accept implements DelegationFilter
+func (f StakingTimeFilter) Accept(btcDel *types.Delegation, _ *types.StakingParams) (bool, string) {
+	stakingTime := btcDel.GetStakingTime()
+	if f.Min != 0 && stakingTime < f.Min {
+		return false, fmt.Sprintf("the staking time %d is below the minimum %d", stakingTime, f.Min)
+	}
+	if f.Max != 0 && stakingTime > f.Max {
+		return false, fmt.Sprintf("the staking time %d is above the maximum %d", stakingTime, f.Max)
+	}
+
+	return true, ""
+}
+
+// RegisterDelegationFilter appends the given filter to the chain of filters
+// which every delegation must pass before it is signed, after the built-in
+// ones. It must be called before the emulator is started.
+func (ce *CovenantEmulator) RegisterDelegationFilter(filter DelegationFilter) {
+	ce.filters = append(ce.filters, filter)
+}
+
+// acceptDelegation runs the given delegation through the chain of filters
+// under the given params, returning the reason of the first filter rejecting it
+func (ce *CovenantEmulator) acceptDelegation(btcDel *types.Delegation, params *types.StakingParams, logger *zap.Logger) (bool, string) {
+	if btcDel == nil {
+		// let signDelegation report the error
+		return true, ""
+	}
+
+	for _, filter := range ce.filters {
+		if ok, reason := filter.Accept(btcDel, params); !ok {
+			logger.Info("skipping the delegation rejected by a filter", zap.String("reason", reason))
+			return false, reason
+		}
+	}
+
+	return true, ""
+}
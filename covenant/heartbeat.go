@@ -0,0 +1,99 @@
+package covenant
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	covcfg "github.com/babylonchain/covenant-emulator/config"
+)
+
+// heartbeater pings the URL of an external monitor upon successful rounds
+// of the submission loop, at most once per interval. If the pings stop,
+// e.g., because the emulator stalls or dies, the monitor alerts.
+type heartbeater struct {
+	url      string
+	interval time.Duration
+	client   *http.Client
+	logger   *zap.Logger
+
+	mu       sync.Mutex
+	lastBeat time.Time
+
+	// sending is set while a ping is in progress so that
+	// a slow monitor does not pile up requests
+	sending atomic.Bool
+}
+
+// newHeartbeater returns nil if no heartbeat URL is configured
+func newHeartbeater(cfg *covcfg.HeartbeatConfig, logger *zap.Logger) *heartbeater {
+	if cfg == nil || cfg.URL == "" {
+		return nil
+	}
+
+	return &heartbeater{
+		url:      cfg.URL,
+		interval: cfg.Interval,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		logger:   logger,
+	}
+}
+
+// due returns whether a heartbeat should be sent at the given time
+// and if so, marks it as sent
+func (h *heartbeater) due(now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.lastBeat.IsZero() && now.Sub(h.lastBeat) < h.interval {
+		return false
+	}
+	h.lastBeat = now
+
+	return true
+}
+
+func (h *heartbeater) ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// beat sends a heartbeat in the background if it is due
+func (ce *CovenantEmulator) beat() {
+	h := ce.heartbeat
+	if h == nil || !h.due(time.Now()) || !h.sending.CompareAndSwap(false, true) {
+		return
+	}
+
+	ce.wg.Add(1)
+	go func() {
+		defer ce.wg.Done()
+		defer h.sending.Store(false)
+
+		ctx, cancel := ce.quitContext()
+		defer cancel()
+
+		if err := h.ping(ctx); err != nil {
+			h.logger.Warn("failed to send the heartbeat", zap.String("url", h.url), zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,226 @@
+package covenant
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/avast/retry-go/v4"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"go.uber.org/zap"
+
+	covcfg "github.com/babylonchain/covenant-emulator/config"
+	"github.com/babylonchain/covenant-emulator/keyring"
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// initKeyring opens the keyring and creates the controller of the covenant key,
+// retrying as configured since some keyring backends are not instantly
+// available, e.g., those backed by a networked or mounted filesystem
+func initKeyring(config *covcfg.Config, input *strings.Reader, logger *zap.Logger) (*keyring.ChainKeyringController, error) {
+	attempts := uint(config.KeyringInitAttempts)
+	if attempts == 0 {
+		attempts = 1
+	}
+
+	var kc *keyring.ChainKeyringController
+	if err := retry.Do(func() error {
+		kr, err := keyring.CreateKeyring(
+			config.BabylonConfig.KeyDirectory,
+			config.BabylonConfig.ChainID,
+			config.BabylonConfig.KeyringBackend,
+			input,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create keyring: %w", err)
+		}
+
+		kc, err = keyring.NewChainKeyringControllerWithKeyring(kr, config.BabylonConfig.Key, input)
+		return err
+	}, retry.Attempts(attempts), retry.Delay(config.KeyringInitRetryDelay), RtyErr, retry.OnRetry(func(n uint, err error) {
+		logger.Warn(
+			"failed to initialize the keyring",
+			zap.Uint("attempt", n+1),
+			zap.Uint("max_attempts", attempts),
+			zap.Error(err),
+		)
+	})); err != nil {
+		return nil, err
+	}
+
+	return kc, nil
+}
+
+// createKeyIfMissing creates the covenant key in the keyring if it does not
+// exist yet. The key is only created if the key is not found; any other
+// failure of reading the keyring, e.g., a wrong passphrase, is returned.
+func createKeyIfMissing(kc *keyring.ChainKeyringController, keyName, passphrase string, logger *zap.Logger) error {
+	exists, err := kc.HasChainKey(passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to check covenant key %s: %w", keyName, err)
+	}
+	if exists {
+		return nil
+	}
+
+	keyInfo, err := kc.CreateChainKey(passphrase, "")
+	if err != nil {
+		return fmt.Errorf("failed to create covenant key %s: %w", keyName, err)
+	}
+
+	logger.Warn("created a new covenant key as it is not found in the keyring, "+
+		"register the public key in the covenant committee before it can sign",
+		zap.String("key_name", keyName),
+		zap.String("pk", hex.EncodeToString(schnorr.SerializePubKey(keyInfo.PublicKey))),
+	)
+
+	return nil
+}
+
+// ListCovenantKeys returns the names and pks of the keys in the keyring of the
+// emulator, e.g., to check which key is the one of the committee
+func (ce *CovenantEmulator) ListCovenantKeys() ([]types.ChainKeyInfo, error) {
+	keyInfos, err := ce.kc.ListChainKeys(ce.passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list covenant keys: %w", err)
+	}
+
+	keys := make([]types.ChainKeyInfo, 0, len(keyInfos))
+	for _, keyInfo := range keyInfos {
+		keys = append(keys, *keyInfo)
+	}
+
+	return keys, nil
+}
+
+func (ce *CovenantEmulator) getPrivKey(logger *zap.Logger) (*btcec.PrivateKey, error) {
+	ce.keyringMu.Lock()
+	sdkPrivKey, err := ce.kc.GetChainPrivKey(ce.passphrase)
+	ce.keyringMu.Unlock()
+	if err != nil {
+		failures := ce.keyringUnlockFailures.Add(1)
+		ce.metrics.KeyringUnlockFailures.Inc()
+		logger.Error(
+			"failed to unlock the covenant key, no signatures can be produced until this is fixed; "+
+				"check that the passphrase is correct, the key exists in the keyring, "+
+				"and the keyring backend is available",
+			zap.String("key_name", ce.config.BabylonConfig.Key),
+			zap.String("keyring_backend", ce.config.BabylonConfig.KeyringBackend),
+			zap.Uint32("consecutive_failures", failures),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("%w: %w", ErrKeyringUnlock, err)
+	}
+	ce.keyringUnlockFailures.Store(0)
+
+	privKey, _ := btcec.PrivKeyFromBytes(sdkPrivKey.Key)
+
+	return privKey, nil
+}
+
+// shouldHaltOnKeyringFailures returns whether the given error is a keyring
+// unlock failure and the consecutive failures reach the configured limit, in
+// which case the signing loop should halt
+func (ce *CovenantEmulator) shouldHaltOnKeyringFailures(err error) bool {
+	if !ce.keyringFailuresExceeded(err) {
+		return false
+	}
+
+	ce.logger.Error(
+		"halting the covenant signature submission loop due to repeated keyring unlock failures, "+
+			"restart the daemon after fixing the keyring",
+		zap.Uint32("consecutive_failures", ce.keyringUnlockFailures.Load()),
+	)
+
+	return true
+}
+
+// keyringFailuresExceeded returns whether the given error is a failure to
+// unlock the covenant key which reaches the configured limit of such failures
+func (ce *CovenantEmulator) keyringFailuresExceeded(err error) bool {
+	limit := ce.config.MaxKeyringUnlockFailures
+	return errors.Is(err, ErrKeyringUnlock) && limit != 0 && ce.keyringUnlockFailures.Load() >= limit
+}
+
+func CreateCovenantKey(keyringDir, chainID, keyName, backend, passphrase, hdPath string) (*types.ChainKeyInfo, error) {
+	krController, err := newKeyringController(keyringDir, chainID, keyName, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	return krController.CreateChainKey(passphrase, hdPath)
+}
+
+// ExportCovenantKey exports the covenant key to the given path as an
+// ASCII-armored private key encrypted with the given encryption passphrase
+func ExportCovenantKey(keyringDir, chainID, keyName, backend, passphrase, outPath, encPassphrase string) error {
+	krController, err := newKeyringController(keyringDir, chainID, keyName, backend)
+	if err != nil {
+		return err
+	}
+
+	armor, err := krController.ExportChainKey(passphrase, encPassphrase)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(outPath, []byte(armor), 0600); err != nil {
+		return fmt.Errorf("failed to write the exported key to %s: %w", outPath, err)
+	}
+
+	return nil
+}
+
+// ImportCovenantKey imports the covenant key exported by ExportCovenantKey from
+// the given path. If expectedPk is not nil, the imported key must correspond to
+// it, e.g., the pk of this covenant in the committee, otherwise the imported
+// key is removed and an error is returned.
+func ImportCovenantKey(
+	keyringDir, chainID, keyName, backend, passphrase, inPath, encPassphrase string,
+	expectedPk *btcec.PublicKey,
+) (*btcec.PublicKey, error) {
+	armor, err := os.ReadFile(inPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the exported key from %s: %w", inPath, err)
+	}
+
+	krController, err := newKeyringController(keyringDir, chainID, keyName, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	pk, err := krController.ImportChainKey(string(armor), encPassphrase, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if expectedPk != nil && !bytes.Equal(schnorr.SerializePubKey(pk), schnorr.SerializePubKey(expectedPk)) {
+		if err := krController.DeleteChainKey(passphrase); err != nil {
+			return nil, fmt.Errorf("failed to remove the imported key %s: %w", keyName, err)
+		}
+		return nil, fmt.Errorf("the pk %s of the imported key does not match the expected covenant pk %s",
+			hex.EncodeToString(schnorr.SerializePubKey(pk)),
+			hex.EncodeToString(schnorr.SerializePubKey(expectedPk)))
+	}
+
+	return pk, nil
+}
+
+func newKeyringController(keyringDir, chainID, keyName, backend string) (*keyring.ChainKeyringController, error) {
+	sdkCtx, err := keyring.CreateClientCtx(
+		keyringDir, chainID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return keyring.NewChainKeyringController(
+		sdkCtx,
+		keyName,
+		backend,
+	)
+}
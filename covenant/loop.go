@@ -0,0 +1,192 @@
+package covenant
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// covenantSigSubmissionLoop is the reactor to submit Covenant signature for BTC delegations
+func (ce *CovenantEmulator) covenantSigSubmissionLoop() {
+	defer ce.wg.Done()
+
+	interval := ce.config.QueryInterval
+	limit := ce.config.DelegationLimit
+	covenantSigTicker := time.NewTicker(interval)
+
+	for {
+		select {
+		case <-covenantSigTicker.C:
+			if !ce.breaker.allow(time.Now()) {
+				ce.logger.Debug("the circuit breaker is open, skipping the round")
+				continue
+			}
+			ce.metrics.ClientBreakerState.Set(float64(ce.breaker.getState()))
+
+			// a heartbeat is only sent if the round succeeds with fresh params
+			// and no batch fails
+			healthy := true
+
+			// 0. Update slashing address in case it is changed upon governance proposal
+			if err := ce.UpdateParams(); err != nil {
+				healthy = false
+				if errors.Is(err, ErrInvalidParams) {
+					// the stale params are not used as the chain no longer accepts them
+					continue
+				}
+				ce.logger.Debug("failed to get staking params", zap.Error(err))
+				ce.recordClientResult(err)
+				if !ce.canUseStaleParams() {
+					continue
+				}
+				ce.logger.Warn("using the last known staking params",
+					zap.Duration("params_age", ce.paramsAge()),
+				)
+			}
+
+			// 1. Get all pending delegations
+			dels, err := ce.queryPendingWithRetry(limit)
+			ce.recordClientResult(err)
+			if err != nil {
+				ce.logger.Warn("failed to get pending delegations, skipping the round", zap.Error(err))
+				continue
+			}
+			ce.logger.Debug("queried the pending delegations", zap.Int("num_delegations", len(dels)))
+			statsBefore := ce.Metrics()
+			ce.emptyTickLog.observe(ce.logger, len(dels), time.Now())
+			if limit != 0 && uint64(len(dels)) == limit {
+				ce.metrics.DelegationLimitReached.Inc()
+				ce.logger.Warn("the number of pending delegations reaches the delegation limit, "+
+					"there might be more pending delegations than fetched; consider raising the limit",
+					zap.Uint64("delegation_limit", limit),
+				)
+			}
+			// 1.1. Check that our submitted sigs are still valid
+			ce.reconcileIfDue(dels)
+			ce.recordQuorumProgress(dels)
+
+			// 2. Remove delegations that are not signed in this round
+			sanitizedDels, err := ce.sanitizeDelegations(dels)
+			if err != nil {
+				ce.logger.Debug("failed to sanitize pending delegations, skipping the round", zap.Error(err))
+				continue
+			}
+
+			// 2.1. A standby only precomputes the sigs
+			if ce.standby.Load() {
+				err := ce.precomputeSigs(sanitizedDels)
+				if ce.shouldHaltOnKeyringFailures(err) {
+					return
+				}
+				ce.recordTick(len(dels), statsBefore)
+				if healthy && err == nil {
+					ce.beat()
+				}
+				continue
+			}
+
+			// 2.2. Defer delegations to the processing windows unless they are
+			// close to expiry
+			sanitizedDels, err = ce.removeOutsideWindow(sanitizedDels)
+			if err != nil {
+				ce.logger.Debug("failed to check expiry of delegations outside the processing windows", zap.Error(err))
+				continue
+			}
+
+			// 3. Split delegations into batches for submission
+			batches := ce.delegationsToBatches(sanitizedDels)
+			for i, err := range ce.submitBatches(batches) {
+				delBatch := batches[i]
+				if errors.Is(err, ErrShuttingDown) {
+					return
+				}
+				if errors.Is(err, ErrParamsChanged) {
+					ce.logger.Info("discarded covenant signatures computed against stale staking params",
+						zap.Int("delegations", len(delBatch)))
+					continue
+				}
+				if err != nil {
+					healthy = false
+					ce.logger.Error(
+						"failed to submit covenant signatures for BTC delegations",
+						zap.Error(err),
+					)
+				}
+				if ce.shouldHaltOnKeyringFailures(err) {
+					return
+				}
+			}
+
+			ce.recordTick(len(dels), statsBefore)
+			if healthy {
+				ce.beat()
+			}
+
+		case <-ce.quit:
+			ce.logger.Debug("exiting covenant signature submission loop")
+			return
+		}
+	}
+
+}
+
+// stopsSubmissionLoop returns whether the given error of a batch stops the
+// submission loop
+func (ce *CovenantEmulator) stopsSubmissionLoop(err error) bool {
+	return errors.Is(err, ErrShuttingDown) || ce.keyringFailuresExceeded(err)
+}
+
+// recordQuorumProgress sets the quorum progress gauge to the number of the given
+// pending delegations at each number of covenant sigs out of the quorum
+func (ce *CovenantEmulator) recordQuorumProgress(dels []*types.Delegation) {
+	quorum := ce.params.Load().CovenantQuorum
+	counts := make([]int, quorum+1)
+	for _, del := range dels {
+		numSigs := uint32(len(del.CovenantSigs))
+		if numSigs > quorum {
+			numSigs = quorum
+		}
+		counts[numSigs]++
+	}
+
+	ce.metrics.QuorumProgress.Reset()
+	for numSigs, count := range counts {
+		ce.metrics.QuorumProgress.WithLabelValues(fmt.Sprintf("%d/%d", numSigs, quorum)).Set(float64(count))
+	}
+}
+
+// recordTick records the metrics and logs the summary of a round of the
+// submission loop which has seen numDels pending delegations. The outcomes
+// are the changes of the signing counters since statsBefore. Delegations which
+// are neither signed nor failed in the round, e.g., deferred or already signed,
+// count as skipped.
+func (ce *CovenantEmulator) recordTick(numDels int, statsBefore MetricsSnapshot) {
+	var statsAfter MetricsSnapshot
+	ce.stats.update(func(s *MetricsSnapshot) {
+		s.LastLoopTime = time.Now()
+		statsAfter = *s
+	})
+
+	signed := statsAfter.Signed - statsBefore.Signed
+	failed := statsAfter.Failed - statsBefore.Failed
+	var skipped uint64
+	if processed := signed + failed; uint64(numDels) > processed {
+		skipped = uint64(numDels) - processed
+	}
+
+	ce.metrics.TickDelegations.Add(float64(numDels))
+	ce.metrics.TickOutcomes.WithLabelValues(string(OutcomeSigned)).Add(float64(signed))
+	ce.metrics.TickOutcomes.WithLabelValues(string(OutcomeSkipped)).Add(float64(skipped))
+	ce.metrics.TickOutcomes.WithLabelValues(string(OutcomeFailed)).Add(float64(failed))
+
+	ce.logger.Info("finished the round of the submission loop",
+		zap.Int("delegations", numDels),
+		zap.Uint64("signed", signed),
+		zap.Uint64("skipped", skipped),
+		zap.Uint64("failed", failed),
+	)
+}
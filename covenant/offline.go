@@ -0,0 +1,73 @@
+package covenant
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// BuildCovenantSigsTx signs the given delegations and builds the tx submitting
+// the sigs without signing it by the submitter account, so that the sigs can be
+// produced where the covenant key is while the tx is signed and broadcast by a
+// separate cold signer. Delegations that already have a covenant quorum are
+// skipped, and nil is returned if none of them needs to be signed.
+func (ce *CovenantEmulator) BuildCovenantSigsTx(btcDels []*types.Delegation, gasLimit uint64) ([]byte, error) {
+	if len(btcDels) == 0 {
+		return nil, fmt.Errorf("no delegations")
+	}
+
+	params := ce.params.Load()
+	covenantSigs := make([]*types.CovenantSigs, 0, len(btcDels))
+	for _, btcDel := range btcDels {
+		delLogger := ce.logger.With(zap.String("correlation_id", newCorrelationID()))
+		covSigs, err := ce.signDelegation(btcDel, params, delLogger, nil)
+		if errors.Is(err, ErrQuorumAlreadyReached) {
+			delLogger.Debug("skipping the delegation", zap.Error(err))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		covenantSigs = append(covenantSigs, covSigs)
+	}
+
+	if len(covenantSigs) == 0 {
+		return nil, nil
+	}
+
+	txJSON, err := ce.cc.BuildCovenantSigsTx(covenantSigs, gasLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the covenant sigs tx: %w", err)
+	}
+
+	ce.logger.Info("built the unsigned covenant sigs tx",
+		zap.Int("num_delegations", len(covenantSigs)),
+		zap.Uint64("gas_limit", gasLimit),
+	)
+
+	return txJSON, nil
+}
+
+// EstimateSubmissionFee signs the given delegation and simulates the tx
+// submitting the sigs without broadcasting it, returning the fee and the gas
+// that the submission is expected to cost
+func (ce *CovenantEmulator) EstimateSubmissionFee(btcDel *types.Delegation) (string, uint64, error) {
+	if err := ce.UpdateParams(); err != nil {
+		return "", 0, fmt.Errorf("failed to get staking params: %w", err)
+	}
+
+	covSigs, err := ce.signDelegation(btcDel, ce.params.Load(), ce.logger, nil)
+	if err != nil {
+		return "", 0, err
+	}
+
+	gas, fee, err := ce.cc.EstimateCovenantSigsFee([]*types.CovenantSigs{covSigs})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to estimate the fee of the covenant sigs tx: %w", err)
+	}
+
+	return fee, gas, nil
+}
@@ -0,0 +1,270 @@
+package covenant
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/clientcontroller"
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// ParamsProvider provides the staking params the emulator signs against
+type ParamsProvider interface {
+	StakingParams() (*types.StakingParams, error)
+}
+
+// clientParamsProvider queries the staking params from the consumer chain
+// with retries
+type clientParamsProvider struct {
+	cc     clientcontroller.ClientController
+	logger *zap.Logger
+}
+
+func newClientParamsProvider(cc clientcontroller.ClientController, logger *zap.Logger) *clientParamsProvider {
+	return &clientParamsProvider{cc: cc, logger: logger}
+}
+
+func (p *clientParamsProvider) StakingParams() (*types.StakingParams, error) {
+	var (
+		params *types.StakingParams
+		err    error
+	)
+
+	if err := retry.Do(func() error {
+		params, err = p.cc.QueryStakingParams()
+		if errors.Is(err, clientcontroller.ErrInvalidSlashingAddress) {
+			// retrying does not help until the params are fixed on chain
+			return retry.Unrecoverable(err)
+		}
+		if err != nil {
+			return err
+		}
+		return nil
+	}, RtyAtt, RtyDel, RtyErr, retry.OnRetry(func(n uint, err error) {
+		p.logger.Debug(
+			"failed to query the consumer chain for the staking params",
+			zap.Uint("attempt", n+1),
+			zap.Uint("max_attempts", RtyAttNum),
+			zap.Error(err),
+		)
+	})); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// CachedParamsProvider is a read-through cache of staking params in front of
+// another provider, which can be shared by the emulators in the same process
+// to reduce the load of querying the params.
+//
+// The cached params can be up to ttl older than the params on the consumer
+// chain. Signatures made in the meantime against params changed by governance,
+// e.g., a new slashing address or covenant committee, are rejected by the
+// consumer chain and retried after the cache expires, so the ttl should be
+// short compared to how long delegations are allowed to stay pending.
+type CachedParamsProvider struct {
+	mu        sync.Mutex
+	provider  ParamsProvider
+	ttl       time.Duration
+	params    *types.StakingParams
+	fetchedAt time.Time
+}
+
+// NewCachedParamsProvider creates a cache of the params of the given provider
+// which expire after ttl
+func NewCachedParamsProvider(provider ParamsProvider, ttl time.Duration) *CachedParamsProvider {
+	return &CachedParamsProvider{
+		provider: provider,
+		ttl:      ttl,
+	}
+}
+
+// StakingParams returns the cached params if they have not expired, or
+// otherwise fetches them from the underlying provider
+func (c *CachedParamsProvider) StakingParams() (*types.StakingParams, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.params != nil && time.Since(c.fetchedAt) < c.ttl {
+		return c.params, nil
+	}
+
+	params, err := c.provider.StakingParams()
+	if err != nil {
+		return nil, err
+	}
+	c.params = params
+	c.fetchedAt = time.Now()
+
+	return params, nil
+}
+
+// SetParamsProvider sets the provider of staking params, e.g., a cache shared
+// by multiple emulators. It must be called before the emulator is started.
+func (ce *CovenantEmulator) SetParamsProvider(provider ParamsProvider) {
+	ce.paramsProvider = provider
+}
+
+func (ce *CovenantEmulator) UpdateParams() error {
+	ce.paramsMu.Lock()
+	defer ce.paramsMu.Unlock()
+
+	params, err := ce.paramsProvider.StakingParams()
+	if errors.Is(err, clientcontroller.ErrInvalidSlashingAddress) {
+		return ce.invalidateParams(err)
+	}
+	if err != nil {
+		return err
+	}
+	if params.SlashingAddress == nil {
+		return ce.invalidateParams(fmt.Errorf("empty slashing address in the staking params"))
+	}
+	if ce.paramsInvalid {
+		ce.logger.Info("the staking params are valid again, resuming signing")
+		ce.paramsInvalid = false
+		ce.metrics.ParamsInvalid.Set(0)
+	}
+	prevParams := ce.params.Load()
+	first := prevParams == nil
+	changed := !first && !prevParams.Equal(params)
+	// the params are stored before the version is increased, so that a pass
+	// loading the version before the params never signs against stale params
+	// without noticing
+	ce.params.Store(params)
+	if changed {
+		ce.paramsVersion.Add(1)
+	}
+	ce.paramsChangeLog.observe(ce.logger, changed, time.Now())
+	ce.paramsUpdatedAt = time.Now()
+
+	if err := ce.CheckCommitteeViability(); err != nil {
+		ce.logger.Error("the covenant committee cannot activate delegations, "+
+			"check the staking params on the consumer chain",
+			zap.Error(err),
+		)
+	}
+	if first || changed {
+		ce.checkSkippingPolicies()
+	}
+
+	return nil
+}
+
+// invalidateParams halts signing as the staking params on the consumer chain
+// are invalid, which is logged once until the params become valid again
+func (ce *CovenantEmulator) invalidateParams(err error) error {
+	if !ce.paramsInvalid {
+		ce.logger.Error("CRITICAL: the staking params on the consumer chain are invalid, "+
+			"signing is halted until they are fixed",
+			zap.Error(err),
+		)
+		ce.paramsInvalid = true
+		ce.metrics.ParamsInvalid.Set(1)
+	}
+
+	return fmt.Errorf("%w: %w", ErrInvalidParams, err)
+}
+
+// canUseStaleParams returns whether the last known staking params are
+// recent enough to be used when querying the params fails
+func (ce *CovenantEmulator) canUseStaleParams() bool {
+	maxAge := ce.config.MaxParamsAge
+	return maxAge != 0 && ce.params.Load() != nil && ce.paramsAge() <= maxAge
+}
+
+// paramsAge returns the time elapsed since the params are last queried
+func (ce *CovenantEmulator) paramsAge() time.Duration {
+	ce.paramsMu.Lock()
+	defer ce.paramsMu.Unlock()
+
+	return time.Since(ce.paramsUpdatedAt)
+}
+
+// btcNetOf returns the BTC network of the consumer chain of the given params,
+// which defaults to the configured network
+func (ce *CovenantEmulator) btcNetOf(params *types.StakingParams) *chaincfg.Params {
+	if params != nil && params.BTCNetParams != nil {
+		return params.BTCNetParams
+	}
+
+	return &ce.config.BTCNetParams
+}
+
+// CheckCommitteeViability checks that the covenant committee in the current
+// staking params can reach a quorum and that this covenant is a member of it.
+// It warns if the committee has no redundancy, i.e., every member is needed
+// to reach the quorum.
+func (ce *CovenantEmulator) CheckCommitteeViability() error {
+	params := ce.params.Load()
+	if params == nil {
+		return fmt.Errorf("empty staking params")
+	}
+
+	// duplicate members count once towards the quorum
+	dups := duplicateCovenantPks(params.CovenantPks)
+	if len(dups) > 0 {
+		ce.logger.Warn("the covenant committee contains duplicate pks, "+
+			"delegations cannot be signed until the staking params are fixed",
+			zap.Strings("duplicate_pks", dups),
+		)
+	}
+
+	quorum := params.CovenantQuorum
+	committeeSize := len(params.CovenantPks) - len(dups)
+	if quorum == 0 {
+		return fmt.Errorf("the covenant quorum is zero")
+	}
+	if int(quorum) > committeeSize {
+		return fmt.Errorf("the covenant quorum %d exceeds the committee size %d", quorum, committeeSize)
+	}
+
+	isMember := false
+	for _, covPk := range params.CovenantPks {
+		if bytes.Equal(schnorr.SerializePubKey(covPk), schnorr.SerializePubKey(ce.pk)) {
+			isMember = true
+			break
+		}
+	}
+	if !isMember {
+		return fmt.Errorf("the covenant pk %s is not in the covenant committee",
+			hex.EncodeToString(schnorr.SerializePubKey(ce.pk)))
+	}
+
+	if int(quorum) == committeeSize {
+		ce.logger.Warn("the covenant quorum equals the committee size, "+
+			"a single unavailable member blocks the activation of delegations",
+			zap.Uint32("quorum", quorum),
+			zap.Int("committee_size", committeeSize),
+		)
+	}
+
+	return nil
+}
+
+// duplicateCovenantPks returns the hex of the pks which appear more than once
+// in the given committee, each reported once per extra occurrence
+func duplicateCovenantPks(pks []*btcec.PublicKey) []string {
+	seen := make(map[string]struct{}, len(pks))
+	var dups []string
+	for _, pk := range pks {
+		pkHex := hex.EncodeToString(schnorr.SerializePubKey(pk))
+		if _, ok := seen[pkHex]; ok {
+			dups = append(dups, pkHex)
+			continue
+		}
+		seen[pkHex] = struct{}{}
+	}
+
+	return dups
+}
@@ -0,0 +1,37 @@
+package covenant
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// paramsChangeLog coalesces the logs of staking params changes so that when
+// the params flap, at most one log is written per interval which summarizes
+// the changes suppressed since the previous one
+type paramsChangeLog struct {
+	interval   time.Duration
+	lastLogged time.Time
+	suppressed uint64
+}
+
+// observe is called upon every params update with whether the params changed
+func (l *paramsChangeLog) observe(logger *zap.Logger, changed bool, now time.Time) {
+	if changed {
+		l.suppressed++
+	}
+	if l.suppressed == 0 || now.Sub(l.lastLogged) < l.interval {
+		return
+	}
+
+	if l.suppressed == 1 {
+		logger.Info("the staking params changed")
+	} else {
+		logger.Info("the staking params changed repeatedly",
+			zap.Uint64("changes", l.suppressed),
+			zap.Duration("since", now.Sub(l.lastLogged)),
+		)
+	}
+	l.lastLogged = now
+	l.suppressed = 0
+}
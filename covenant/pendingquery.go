@@ -0,0 +1,77 @@
+package covenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/avast/retry-go/v4"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// queryPendingWithRetry queries the pending delegations of a round, retrying
+// failed or timed out attempts as configured so that a transiently slow node
+// does not make the round skip signing
+func (ce *CovenantEmulator) queryPendingWithRetry(limit uint64) ([]*types.Delegation, error) {
+	attempts := uint(ce.config.PendingQueryAttempts)
+	if attempts == 0 {
+		attempts = 1
+	}
+
+	var dels []*types.Delegation
+	err := retry.Do(func() error {
+		select {
+		case <-ce.quit:
+			return retry.Unrecoverable(ErrShuttingDown)
+		default:
+		}
+
+		var err error
+		dels, err = ce.queryPendingWithTimeout(limit)
+		return err
+	}, retry.Attempts(attempts), retry.Delay(ce.config.PendingQueryRetryDelay), RtyErr, retry.OnRetry(func(n uint, err error) {
+		ce.logger.Debug(
+			"failed to query the pending delegations",
+			zap.Uint("attempt", n+1),
+			zap.Uint("max_attempts", attempts),
+			zap.Error(err),
+		)
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return dels, nil
+}
+
+// queryPendingWithTimeout queries the pending delegations and cancels the
+// query after the configured timeout or upon shutdown, so that no query is
+// left running against a stalled node
+func (ce *CovenantEmulator) queryPendingWithTimeout(limit uint64) ([]*types.Delegation, error) {
+	ctx, cancel := ce.quitContext()
+	defer cancel()
+
+	timeout := ce.config.PendingQueryTimeout
+	if timeout != 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+		defer cancelTimeout()
+	}
+
+	dels, err := ce.queryPendingDelegations(ctx, limit)
+	if err != nil {
+		select {
+		case <-ce.quit:
+			return nil, retry.Unrecoverable(ErrShuttingDown)
+		default:
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w: querying the pending delegations exceeded %v: %v", ErrQueryTimeout, timeout, err)
+		}
+		return nil, err
+	}
+
+	return dels, nil
+}
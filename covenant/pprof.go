@@ -0,0 +1,58 @@
+package covenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// startPprofServer serves the pprof endpoints at the configured address
+// until the emulator stops
+func (ce *CovenantEmulator) startPprofServer() error {
+	addr := ce.config.PprofAddress
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	// bind before returning so that an unavailable address fails the start
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on pprof address %s: %w", addr, err)
+	}
+
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ce.logger.Info("starting pprof server", zap.String("address", listener.Addr().String()))
+
+	ce.wg.Add(2)
+	go func() {
+		defer ce.wg.Done()
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ce.logger.Error("pprof server failed", zap.Error(err))
+		}
+	}()
+	go func() {
+		defer ce.wg.Done()
+		<-ce.quit
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			ce.logger.Debug("failed to shut down the pprof server", zap.Error(err))
+		}
+	}()
+
+	return nil
+}
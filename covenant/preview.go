@@ -0,0 +1,104 @@
+package covenant
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	bbntypes "github.com/babylonchain/babylon/types"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// DelegationSummary is a read-only summary of a pending delegation
+type DelegationSummary struct {
+	StakingTxHash string   `json:"staking_tx_hash"`
+	TotalSat      uint64   `json:"total_sat"`
+	FpBtcPks      []string `json:"fp_btc_pks"`
+	// SigsNeeded is the number of covenant signatures still missing
+	// for the delegation to reach a covenant quorum
+	SigsNeeded uint32 `json:"sigs_needed"`
+	SignedByUs bool   `json:"signed_by_us"`
+}
+
+// PreviewPending queries up to limit pending delegations and summarizes them
+// without signing anything
+func (ce *CovenantEmulator) PreviewPending(limit uint64) ([]DelegationSummary, error) {
+	if err := ce.UpdateParams(); err != nil {
+		return nil, fmt.Errorf("failed to get staking params: %w", err)
+	}
+	params := ce.params.Load()
+
+	ctx, cancel := ce.quitContext()
+	defer cancel()
+
+	dels, err := ce.cc.QueryPendingDelegations(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending BTC delegations: %w", err)
+	}
+
+	summaries := make([]DelegationSummary, 0, len(dels))
+	for _, del := range dels {
+		stakingMsgTx, _, err := bbntypes.NewBTCTxFromHex(del.StakingTxHex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid staking tx in the delegation: %w", err)
+		}
+
+		fpPks := make([]string, 0, len(del.FpBtcPks))
+		for _, fpPk := range del.FpBtcPks {
+			fpPks = append(fpPks, hex.EncodeToString(schnorr.SerializePubKey(fpPk)))
+		}
+
+		var sigsNeeded uint32
+		if numSigs := uint32(len(del.CovenantSigs)); numSigs < params.CovenantQuorum {
+			sigsNeeded = params.CovenantQuorum - numSigs
+		}
+
+		summaries = append(summaries, DelegationSummary{
+			StakingTxHash: stakingMsgTx.TxHash().String(),
+			TotalSat:      del.TotalSat,
+			FpBtcPks:      fpPks,
+			SigsNeeded:    sigsNeeded,
+			SignedByUs:    ce.isSignedByUs(del),
+		})
+	}
+
+	return summaries, nil
+}
+
+// DelegationSigStatus returns the pks of the covenant committee members which
+// have submitted sigs for the delegation with the given staking tx hash and
+// the pks of those which have not, all hex encoded in BIP-340 format
+func (ce *CovenantEmulator) DelegationSigStatus(stakingTxHash string) (signed []string, missing []string, err error) {
+	hash, err := chainhash.NewHashFromStr(stakingTxHash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid staking tx hash %s: %w", stakingTxHash, err)
+	}
+
+	if err := ce.UpdateParams(); err != nil {
+		return nil, nil, fmt.Errorf("failed to get staking params: %w", err)
+	}
+	params := ce.params.Load()
+
+	del, err := ce.cc.QueryBTCDelegation(*hash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get BTC delegation %s: %w", stakingTxHash, err)
+	}
+
+	signedPks := make(map[string]struct{}, len(del.CovenantSigs))
+	for _, covSig := range del.CovenantSigs {
+		signedPks[hex.EncodeToString(schnorr.SerializePubKey(covSig.Pk))] = struct{}{}
+	}
+
+	signed = make([]string, 0, len(signedPks))
+	missing = make([]string, 0, len(params.CovenantPks))
+	for _, covPk := range params.CovenantPks {
+		pkHex := hex.EncodeToString(schnorr.SerializePubKey(covPk))
+		if _, ok := signedPks[pkHex]; ok {
+			signed = append(signed, pkHex)
+		} else {
+			missing = append(missing, pkHex)
+		}
+	}
+
+	return signed, missing, nil
+}
@@ -0,0 +1,99 @@
+package covenant
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	asig "github.com/babylonchain/babylon/crypto/schnorr-adaptor-signature"
+	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// reconcileIfDue re-verifies our submitted sigs on the given pending
+// delegations if the configured reconciliation interval has elapsed
+func (ce *CovenantEmulator) reconcileIfDue(dels []*types.Delegation) {
+	interval := ce.config.ReconcileInterval
+	if interval == 0 || time.Since(ce.lastReconcile) < interval {
+		return
+	}
+	ce.lastReconcile = time.Now()
+
+	params := ce.params.Load()
+	var numChecked, numInvalid int
+	for _, del := range dels {
+		if !ce.isSignedByUs(del) || del.BtcUndelegation == nil {
+			continue
+		}
+		numChecked++
+		if err := ce.verifyOurSlashingSigs(del, params); err != nil {
+			numInvalid++
+			ce.metrics.InvalidatedSigs.Inc()
+			hash, _ := stakingTxHashOf(del)
+			ce.logger.Error("our submitted covenant signatures are no longer valid under the current staking params, "+
+				"the delegation may not reach a covenant quorum",
+				zap.String("staking_tx_hash", hash),
+				zap.Error(err),
+			)
+		}
+	}
+
+	ce.logger.Debug("reconciled the submitted covenant signatures",
+		zap.Int("checked", numChecked),
+		zap.Int("invalid", numInvalid),
+	)
+}
+
+// verifyOurSlashingSigs verifies that our adaptor sigs on the staking slashing
+// tx of the delegation are valid under the given staking params
+func (ce *CovenantEmulator) verifyOurSlashingSigs(del *types.Delegation, params *types.StakingParams) error {
+	var ourSigs [][]byte
+	for _, covSig := range del.CovenantSigs {
+		if bytes.Equal(schnorr.SerializePubKey(covSig.Pk), schnorr.SerializePubKey(ce.pk)) {
+			ourSigs = covSig.Sigs
+			break
+		}
+	}
+	if len(ourSigs) != len(del.FpBtcPks) {
+		return fmt.Errorf("expected %d adaptor sigs, got %d", len(del.FpBtcPks), len(ourSigs))
+	}
+
+	stakingInfo, _, err := BuildDelegationScripts(del, params, ce.btcNetOf(params))
+	if err != nil {
+		return err
+	}
+	slashingPathInfo, err := stakingInfo.SlashingPathSpendInfo()
+	if err != nil {
+		return err
+	}
+	slashingTx, err := bstypes.NewBTCSlashingTxFromHex(del.SlashingTxHex)
+	if err != nil {
+		return err
+	}
+	encKeys, err := ce.deriveEncKeys(del.FpBtcPks)
+	if err != nil {
+		return err
+	}
+
+	for i, sigBytes := range ourSigs {
+		adaptorSig, err := asig.NewAdaptorSignatureFromBytes(sigBytes)
+		if err != nil {
+			return fmt.Errorf("invalid adaptor sig for finality provider %d: %w", i, err)
+		}
+		if err := slashingTx.EncVerifyAdaptorSignature(
+			stakingInfo.StakingOutput.PkScript,
+			stakingInfo.StakingOutput.Value,
+			slashingPathInfo.GetPkScriptPath(),
+			ce.pk,
+			encKeys[i],
+			adaptorSig,
+		); err != nil {
+			return fmt.Errorf("invalid adaptor sig for finality provider %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
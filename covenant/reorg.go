@@ -0,0 +1,56 @@
+package covenant
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// removeReorged removes the given pending delegations of which the staking tx
+// is no longer in the canonical BTC chain as seen by the BTC light client of
+// the consumer chain, i.e., the tip is below the inclusion height after a
+// reorg. Our sigs are moot for such a delegation as it cannot be activated
+// unless the staking tx is included again, so it is also removed from the
+// tracking of failures and submissions. Each of them is logged once.
+// NOTE: reorgs which replace the inclusion block without lowering the tip are
+// not detected, as the block hash of the inclusion is not known.
+func (ce *CovenantEmulator) removeReorged(dels []*types.Delegation) ([]*types.Delegation, error) {
+	if !ce.config.DetectStakingReorgs || len(dels) == 0 {
+		return dels, nil
+	}
+	tipHeight, err := ce.cc.QueryBtcLightClientTipHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	canonical := make([]*types.Delegation, 0, len(dels))
+	for _, del := range dels {
+		if del.StartHeight <= tipHeight {
+			canonical = append(canonical, del)
+			continue
+		}
+
+		hash, ok := stakingTxHashOf(del)
+		if !ok || ce.reorged.contains(hash) {
+			continue
+		}
+		ce.reorged.add(hash)
+		ce.metrics.ReorgedStakingTxs.Inc()
+		ce.backoff.recordSuccess(hash)
+		ce.submissions.forget(hash)
+
+		logger := ce.logger.With(
+			zap.String("staking_tx_hash", hash),
+			zap.Uint64("start_height", del.StartHeight),
+			zap.Uint64("tip_height", tipHeight),
+		)
+		if ce.isSignedByUs(del) {
+			logger.Warn("the staking tx of a delegation we signed is reorged out of the BTC chain, " +
+				"the delegation is not activated unless the staking tx is included again")
+		} else {
+			logger.Info("the staking tx of a pending delegation is reorged out of the BTC chain, deferring the delegation")
+		}
+	}
+
+	return canonical, nil
+}
@@ -0,0 +1,80 @@
+package covenant
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DelegationOutcome is the outcome of processing a delegation
+type DelegationOutcome string
+
+const (
+	OutcomeSigned  DelegationOutcome = "signed"
+	OutcomeSkipped DelegationOutcome = "skipped"
+	OutcomeFailed  DelegationOutcome = "failed"
+)
+
+// DelegationResult is the result of processing a delegation
+type DelegationResult struct {
+	StakingTxHash string            `json:"staking_tx_hash"`
+	Outcome       DelegationOutcome `json:"outcome"`
+	// Reason is the reason of skipping or failing the delegation
+	Reason string `json:"reason,omitempty"`
+	// TxHash is the hash of the tx submitting the signatures
+	TxHash string    `json:"tx_hash,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// ResultSink records the result of every processed delegation, e.g., into a
+// local database that operators can query for reporting
+type ResultSink interface {
+	Record(result DelegationResult) error
+	Close() error
+}
+
+// NoopResultSink is the ResultSink which records nothing
+type NoopResultSink struct{}
+
+func (NoopResultSink) Record(DelegationResult) error { return nil }
+
+func (NoopResultSink) Close() error { return nil }
+
+// RegisterResultSink registers the sink to which the result of every processed
+// delegation is recorded. Unlike the results channel, recording is synchronous
+// with the processing. It must be called before the emulator is started.
+func (ce *CovenantEmulator) RegisterResultSink(sink ResultSink) {
+	ce.sink = sink
+}
+
+// RegisterResults registers the channel to which the result of every processed
+// delegation is written. Writes never block: results are dropped if the channel
+// is full, so consumers should use a buffered channel and keep up with it. It
+// must be called before the emulator is started.
+func (ce *CovenantEmulator) RegisterResults(results chan<- DelegationResult) {
+	ce.results = results
+}
+
+func (ce *CovenantEmulator) reportResult(stakingTxHash string, outcome DelegationOutcome, reason string, txHash string) {
+	result := DelegationResult{
+		StakingTxHash: stakingTxHash,
+		Outcome:       outcome,
+		Reason:        reason,
+		TxHash:        txHash,
+		Time:          time.Now(),
+	}
+	if err := ce.sink.Record(result); err != nil {
+		ce.logger.Warn("failed to record the delegation result",
+			zap.String("staking_tx_hash", stakingTxHash), zap.Error(err))
+	}
+
+	if ce.results == nil {
+		return
+	}
+	select {
+	case ce.results <- result:
+	default:
+		ce.logger.Debug("dropped the delegation result as the results channel is full",
+			zap.String("staking_tx_hash", stakingTxHash))
+	}
+}
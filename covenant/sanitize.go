@@ -0,0 +1,249 @@
+package covenant
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"go.uber.org/zap"
+
+	covcfg "github.com/babylonchain/covenant-emulator/config"
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// sanitizeDelegations removes the given pending delegations which are not
+// signed in this round, i.e., those that do not need the covenant's signature
+// and those that are deferred, and orders the rest by priority. Both the
+// submission loop and the backfill sign only the returned delegations, which
+// are then deferred to the processing windows.
+func (ce *CovenantEmulator) sanitizeDelegations(dels []*types.Delegation) ([]*types.Delegation, error) {
+	ce.forgetSettledSubmissions(dels)
+
+	// defer delegations whose staking tx is reorged out
+	dels, err := ce.removeReorged(dels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check reorgs of staking txs: %w", err)
+	}
+
+	// remove delegations that do not need the covenant's signature
+	dels = ce.removeAlreadySigned(dels)
+	dels = ce.removeStuckSubmissions(dels)
+	if ce.config.OnlyDecidingSig {
+		dels = ce.removeNotDeciding(dels)
+	}
+
+	// defer delegations whose staking tx is not deep enough yet
+	dels, err = ce.removeUnconfirmed(dels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check confirmations of staking txs: %w", err)
+	}
+
+	// defer delegations that failed recently
+	dels = ce.removeBackingOff(dels)
+
+	// order delegations by the configured priority and move delegations
+	// close to expiry to the front
+	ce.sortDelegations(dels)
+	dels, err = ce.prioritizeExpiring(dels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check expiry of delegations: %w", err)
+	}
+
+	// defer delegations exceeding the per finality provider cap
+	return ce.capPerFinalityProvider(dels), nil
+}
+
+// removeAlreadySigned removes any delegations that have already been signed by the covenant
+func (ce *CovenantEmulator) removeAlreadySigned(dels []*types.Delegation) []*types.Delegation {
+	sanitized := make([]*types.Delegation, 0, len(dels))
+
+	for _, del := range dels {
+		delCopy := del
+		if !ce.isSignedByUs(delCopy) {
+			sanitized = append(sanitized, delCopy)
+		}
+	}
+	return sanitized
+}
+
+// isSignedByUs returns whether the delegation already has the signatures of the covenant
+func (ce *CovenantEmulator) isSignedByUs(del *types.Delegation) bool {
+	for _, covSig := range del.CovenantSigs {
+		if bytes.Equal(schnorr.SerializePubKey(covSig.Pk), schnorr.SerializePubKey(ce.pk)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeNotDeciding removes the delegations which our sig would not bring to
+// the covenant quorum, deferring them until the other members sign
+func (ce *CovenantEmulator) removeNotDeciding(dels []*types.Delegation) []*types.Delegation {
+	quorum := ce.params.Load().CovenantQuorum
+	deciding := make([]*types.Delegation, 0, len(dels))
+	for _, del := range dels {
+		if uint32(len(del.CovenantSigs))+1 == quorum {
+			deciding = append(deciding, del)
+		}
+	}
+	if deferred := len(dels) - len(deciding); deferred > 0 {
+		ce.logger.Debug("deferring the delegations which our signature does not bring to a quorum",
+			zap.Int("deferred", deferred),
+		)
+	}
+
+	return deciding
+}
+
+// removeUnconfirmed removes any delegations of which the staking tx has fewer
+// confirmations than required. They remain pending on the consumer chain and
+// thus will be re-evaluated in later rounds.
+func (ce *CovenantEmulator) removeUnconfirmed(dels []*types.Delegation) ([]*types.Delegation, error) {
+	minConfirmations := ce.config.MinStakingConfirmations
+	if minConfirmations == 0 || len(dels) == 0 {
+		return dels, nil
+	}
+
+	tipHeight, err := ce.cc.QueryBtcLightClientTipHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	confirmed := make([]*types.Delegation, 0, len(dels))
+	for _, del := range dels {
+		// the staking tx is included in the block at the start height
+		var confirmations uint64
+		if tipHeight >= del.StartHeight {
+			confirmations = tipHeight - del.StartHeight + 1
+		}
+		if confirmations < minConfirmations {
+			ce.logger.Debug("the staking tx is not deep enough, deferring the delegation",
+				zap.Uint64("start_height", del.StartHeight),
+				zap.Uint64("tip_height", tipHeight),
+				zap.Uint64("confirmations", confirmations),
+				zap.Uint64("min_confirmations", minConfirmations),
+			)
+			continue
+		}
+		confirmed = append(confirmed, del)
+	}
+
+	return confirmed, nil
+}
+
+// sortDelegations orders the given delegations in place according to the
+// configured delegation order. Ties are broken by the staking tx hash.
+func (ce *CovenantEmulator) sortDelegations(dels []*types.Delegation) {
+	order := ce.config.DelegationOrder
+	if order == covcfg.DelegationOrderNone {
+		return
+	}
+
+	hashes := make(map[*types.Delegation]string, len(dels))
+	for _, del := range dels {
+		// undecodable txs are rejected when signing, so they are only ordered here
+		hashes[del], _ = stakingTxHashOf(del)
+	}
+
+	sort.SliceStable(dels, func(i, j int) bool {
+		switch order {
+		case covcfg.DelegationOrderValueDesc:
+			if dels[i].TotalSat != dels[j].TotalSat {
+				return dels[i].TotalSat > dels[j].TotalSat
+			}
+		case covcfg.DelegationOrderOldestFirst:
+			if dels[i].StartHeight != dels[j].StartHeight {
+				return dels[i].StartHeight < dels[j].StartHeight
+			}
+		}
+		return hashes[dels[i]] < hashes[dels[j]]
+	})
+}
+
+// prioritizeExpiring moves the delegations of which the staking timelock
+// expires within the configured window to the front, keeping the relative
+// order otherwise. An alert is logged for each of them as they may never be
+// activated if the covenant quorum is not reached in time.
+func (ce *CovenantEmulator) prioritizeExpiring(dels []*types.Delegation) ([]*types.Delegation, error) {
+	window := ce.config.ExpiryWarningBlocks
+	if window == 0 || len(dels) == 0 {
+		return dels, nil
+	}
+
+	tipHeight, err := ce.cc.QueryBtcLightClientTipHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	expiring := make([]*types.Delegation, 0)
+	others := make([]*types.Delegation, 0, len(dels))
+	for _, del := range dels {
+		if del.EndHeight > tipHeight+window {
+			others = append(others, del)
+			continue
+		}
+
+		var remainingBlocks uint64
+		if del.EndHeight > tipHeight {
+			remainingBlocks = del.EndHeight - tipHeight
+		}
+		hash, _ := stakingTxHashOf(del)
+		ce.logger.Error("the staking timelock of a pending delegation is about to expire without a covenant quorum",
+			zap.String("staking_tx_hash", hash),
+			zap.Uint64("end_height", del.EndHeight),
+			zap.Uint64("tip_height", tipHeight),
+			zap.Uint64("remaining_blocks", remainingBlocks),
+			zap.Int("covenant_sigs", len(del.CovenantSigs)),
+		)
+		expiring = append(expiring, del)
+	}
+	ce.metrics.DelegationsNearExpiry.Set(float64(len(expiring)))
+
+	return append(expiring, others...), nil
+}
+
+// capPerFinalityProvider keeps at most the configured number of delegations
+// per finality provider, in order. A delegation to multiple finality providers
+// counts towards each of them and is deferred if any of them reaches the cap.
+func (ce *CovenantEmulator) capPerFinalityProvider(dels []*types.Delegation) []*types.Delegation {
+	maxPerFp := ce.config.MaxDelegationsPerFpPerTick
+	if maxPerFp == 0 {
+		return dels
+	}
+
+	counts := make(map[string]uint64)
+	kept := make([]*types.Delegation, 0, len(dels))
+	deferred := 0
+	for _, del := range dels {
+		fpPkHexes := make([]string, 0, len(del.FpBtcPks))
+		exceeded := false
+		for _, fpPk := range del.FpBtcPks {
+			fpPkHex := hex.EncodeToString(schnorr.SerializePubKey(fpPk))
+			if counts[fpPkHex] >= maxPerFp {
+				exceeded = true
+				break
+			}
+			fpPkHexes = append(fpPkHexes, fpPkHex)
+		}
+		if exceeded {
+			deferred++
+			continue
+		}
+		for _, fpPkHex := range fpPkHexes {
+			counts[fpPkHex]++
+		}
+		kept = append(kept, del)
+	}
+
+	if deferred > 0 {
+		ce.logger.Debug("deferring delegations exceeding the per finality provider cap",
+			zap.Int("deferred", deferred),
+			zap.Uint64("max_delegations_per_fp", maxPerFp),
+		)
+	}
+
+	return kept
+}
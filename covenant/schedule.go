@@ -0,0 +1,100 @@
+package covenant
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	covcfg "github.com/babylonchain/covenant-emulator/config"
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// processingSchedule restricts signing and submitting to the configured
+// daily windows, e.g., the periods of low fees on the consumer chain
+type processingSchedule struct {
+	windows      []covcfg.ProcessingWindow
+	expiryBlocks uint64
+
+	// closed is set while outside the windows, so that only the
+	// transitions are logged
+	closed bool
+}
+
+// newProcessingSchedule parses the given windows, which are validated
+// with the config
+func newProcessingSchedule(windows []string, expiryBlocks uint64) (processingSchedule, error) {
+	s := processingSchedule{expiryBlocks: expiryBlocks}
+	for _, w := range windows {
+		window, err := covcfg.ParseProcessingWindow(w)
+		if err != nil {
+			return processingSchedule{}, err
+		}
+		s.windows = append(s.windows, window)
+	}
+
+	return s, nil
+}
+
+// isOpen returns whether the given time is within any of the windows, which
+// is always the case if no window is configured
+func (s *processingSchedule) isOpen(now time.Time) bool {
+	if len(s.windows) == 0 {
+		return true
+	}
+	for _, w := range s.windows {
+		if w.Contains(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeOutsideWindow defers the given delegations to the next processing
+// window if the current time is outside all of them. Delegations of which
+// the staking timelock expires within the configured number of blocks are
+// kept regardless, so that they are not left to expire while waiting.
+func (ce *CovenantEmulator) removeOutsideWindow(dels []*types.Delegation) ([]*types.Delegation, error) {
+	open := ce.schedule.isOpen(time.Now())
+	if open != !ce.schedule.closed {
+		if open {
+			ce.logger.Info("the processing window is open, resuming signing")
+		} else {
+			ce.logger.Info("outside the processing windows, deferring signing of pending delegations")
+		}
+		ce.schedule.closed = !open
+	}
+	if open || len(dels) == 0 {
+		return dels, nil
+	}
+
+	window := ce.schedule.expiryBlocks
+	if window == 0 {
+		ce.logger.Debug("deferring delegations to the next processing window", zap.Int("num_delegations", len(dels)))
+		return nil, nil
+	}
+
+	tipHeight, err := ce.cc.QueryBtcLightClientTipHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	expiring := make([]*types.Delegation, 0)
+	for _, del := range dels {
+		if del.EndHeight <= tipHeight+window {
+			expiring = append(expiring, del)
+		}
+	}
+	ce.logger.Debug("deferring delegations to the next processing window",
+		zap.Int("num_delegations", len(dels)-len(expiring)),
+		zap.Int("num_expiring", len(expiring)),
+	)
+	if len(expiring) != 0 {
+		ce.logger.Info("signing delegations close to expiry outside the processing windows",
+			zap.Int("num_delegations", len(expiring)),
+			zap.Uint64("tip_height", tipHeight),
+		)
+	}
+
+	return expiring, nil
+}
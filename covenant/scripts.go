@@ -0,0 +1,149 @@
+package covenant
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/babylonchain/babylon/btcstaking"
+	bbntypes "github.com/babylonchain/babylon/types"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// BuildDelegationScripts reconstructs the taproot scripts of the staking output and the
+// unbonding output of the given delegation under the given staking params. It allows
+// external tools to independently verify covenant signatures and to debug script
+// mismatches.
+func BuildDelegationScripts(
+	btcDel *types.Delegation,
+	params *types.StakingParams,
+	btcNet *chaincfg.Params,
+) (*btcstaking.StakingInfo, *btcstaking.UnbondingInfo, error) {
+	if btcDel == nil {
+		return nil, nil, fmt.Errorf("empty delegation")
+	}
+
+	if btcDel.BtcUndelegation == nil {
+		return nil, nil, fmt.Errorf("empty undelegation")
+	}
+
+	stakingInfo, err := btcstaking.BuildStakingInfo(
+		btcDel.BtcPk,
+		btcDel.FpBtcPks,
+		params.CovenantPks,
+		params.CovenantQuorum,
+		btcDel.GetStakingTime(),
+		btcutil.Amount(btcDel.TotalSat),
+		btcNet,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build staking info: %w", err)
+	}
+
+	unbondingMsgTx, _, err := bbntypes.NewBTCTxFromHex(btcDel.BtcUndelegation.UnbondingTxHex)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(unbondingMsgTx.TxOut) == 0 {
+		return nil, nil, fmt.Errorf("%w: the unbonding tx has no outputs", ErrInvalidOutputIdx)
+	}
+
+	// the unbonding output cannot hold more than the staked amount
+	unbondingValue := unbondingMsgTx.TxOut[0].Value
+	if unbondingValue <= 0 || uint64(unbondingValue) > btcDel.TotalSat {
+		return nil, nil, fmt.Errorf("%w: unbonding output value %d, staked amount %d",
+			ErrInvalidUnbondingValue, unbondingValue, btcDel.TotalSat)
+	}
+
+	if btcDel.UnbondingTime > math.MaxUint16 {
+		return nil, nil, fmt.Errorf("unbonding time %d exceeds the maximum timelock %d",
+			btcDel.UnbondingTime, math.MaxUint16)
+	}
+
+	unbondingInfo, err := btcstaking.BuildUnbondingInfo(
+		btcDel.BtcPk,
+		btcDel.FpBtcPks,
+		params.CovenantPks,
+		params.CovenantQuorum,
+		uint16(btcDel.UnbondingTime),
+		btcutil.Amount(unbondingValue),
+		btcNet,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build unbonding info: %w", err)
+	}
+
+	return stakingInfo, unbondingInfo, nil
+}
+
+// validatePubKeys checks that all the public keys involved in signing the
+// given delegation are valid BIP340 public keys, identifying the first
+// invalid one by its role and index
+func validatePubKeys(btcDel *types.Delegation, params *types.StakingParams) error {
+	if err := validateBIP340PubKey(btcDel.BtcPk); err != nil {
+		return fmt.Errorf("invalid staker pk: %w", err)
+	}
+
+	if len(btcDel.FpBtcPks) == 0 {
+		return fmt.Errorf("empty finality provider pks")
+	}
+	for i, fpPk := range btcDel.FpBtcPks {
+		if err := validateBIP340PubKey(fpPk); err != nil {
+			return fmt.Errorf("invalid finality provider pk at index %d: %w", i, err)
+		}
+	}
+
+	for i, covPk := range params.CovenantPks {
+		if err := validateBIP340PubKey(covPk); err != nil {
+			return fmt.Errorf("invalid covenant pk at index %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateBIP340PubKey checks that the given public key round-trips through
+// its BIP340 x-only encoding
+func validateBIP340PubKey(pk *btcec.PublicKey) error {
+	if pk == nil {
+		return fmt.Errorf("empty public key")
+	}
+
+	if _, err := schnorr.ParsePubKey(schnorr.SerializePubKey(pk)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// errPkScriptMismatch is returned by checkOutputScript when the pk script of
+// the output differs from the expected one
+var errPkScriptMismatch = errors.New("the pk script does not contain the expected spend paths")
+
+// checkOutputScript checks that the output of the given tx at the given index
+// has the same pk script and value as the expected output
+func checkOutputScript(tx *wire.MsgTx, outputIdx uint32, expectedOutput *wire.TxOut) error {
+	if int(outputIdx) >= len(tx.TxOut) {
+		return fmt.Errorf("%w: output index %d, tx has %d outputs", ErrInvalidOutputIdx, outputIdx, len(tx.TxOut))
+	}
+
+	output := tx.TxOut[outputIdx]
+	if !bytes.Equal(output.PkScript, expectedOutput.PkScript) {
+		return fmt.Errorf("%w: output %d", errPkScriptMismatch, outputIdx)
+	}
+
+	if output.Value != expectedOutput.Value {
+		return fmt.Errorf("the value %d of output %d does not match the expected value %d",
+			output.Value, outputIdx, expectedOutput.Value)
+	}
+
+	return nil
+}
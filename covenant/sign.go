@@ -0,0 +1,776 @@
+package covenant
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/babylonchain/babylon/btcstaking"
+	bbntypes "github.com/babylonchain/babylon/types"
+	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/clientcontroller"
+	covcfg "github.com/babylonchain/covenant-emulator/config"
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// AddCovenantSignatures adds Covenant signatures on the given Bitcoin delegations and submits them to Babylon
+// Delegations that already have a covenant quorum are skipped. A nil response is
+// returned if none of the delegations needs to be signed.
+func (ce *CovenantEmulator) AddCovenantSignatures(btcDels []*types.Delegation) (*types.TxResponse, error) {
+	res, _, err := ce.addCovenantSignatures(btcDels, ce.recordClientResult)
+	return res, err
+}
+
+// batchCounts counts the delegations of a batch which are signed and those
+// which are skipped, the rest of which failed. The staking tx hashes of the
+// signed delegations, of which the sigs are submitted, are kept in submitted.
+type batchCounts struct {
+	signed    int
+	skipped   int
+	submitted []chainhash.Hash
+}
+
+// addCovenantSignatures implements AddCovenantSignatures, passing the results
+// of submitting to the consumer chain to the given recordClientResult, so that
+// concurrent submissions can defer recording them. It also returns the counts
+// of the delegations which are signed and skipped.
+func (ce *CovenantEmulator) addCovenantSignatures(
+	btcDels []*types.Delegation,
+	recordClientResult func(err error),
+) (*types.TxResponse, batchCounts, error) {
+	if len(btcDels) == 0 {
+		return nil, batchCounts{}, fmt.Errorf("no delegations")
+	}
+	numDels := uint64(len(btcDels))
+	ce.stats.update(func(s *MetricsSnapshot) { s.InFlight += numDels })
+	defer ce.stats.update(func(s *MetricsSnapshot) { s.InFlight -= numDels })
+	// the version is loaded before the params, see UpdateParams
+	paramsVersion := ce.paramsVersion.Load()
+	params := ce.params.Load()
+
+	var skipped uint64
+	loggers := make([]*zap.Logger, len(btcDels))
+	toSign := make([]bool, len(btcDels))
+	for i, btcDel := range btcDels {
+		loggers[i] = ce.logger.With(zap.String("correlation_id", newCorrelationID()))
+		hash, hashOk := stakingTxHashOf(btcDel)
+		if ok, reason := ce.acceptDelegation(btcDel, params, loggers[i]); !ok {
+			ce.warnIfQuorumBlocked(btcDel, loggers[i])
+			ce.reportResult(hash, OutcomeSkipped, reason, "")
+			skipped++
+			continue
+		}
+		if hashOk && ce.unsignable.contains(hash) {
+			ce.reportResult(hash, OutcomeSkipped, ErrUnsignableDelegation.Error(), "")
+			skipped++
+			continue
+		}
+		toSign[i] = true
+	}
+
+	signed := ce.signDelegations(btcDels, params, loggers, toSign)
+	covenantSigs := make([]*types.CovenantSigs, 0, len(btcDels))
+	delLoggers := make([]*zap.Logger, 0, len(btcDels))
+	for i, btcDel := range btcDels {
+		if !toSign[i] {
+			continue
+		}
+		delLogger := loggers[i]
+		hash, hashOk := stakingTxHashOf(btcDel)
+		covSigs, err := signed(i)
+		if errors.Is(err, ErrQuorumAlreadyReached) {
+			// the quorum is already achieved, skip sending more sigs
+			ce.metrics.QuorumAlreadyReached.Inc()
+			delLogger.Debug("skipping the delegation", zap.Error(err))
+			ce.reportResult(hash, OutcomeSkipped, err.Error(), "")
+			skipped++
+			continue
+		}
+		if errors.Is(err, ErrUnsignableDelegation) {
+			ce.metrics.UnsignableDelegations.Inc()
+			delLogger.Warn("skipping the delegation that cannot be signed by this version",
+				zap.Error(err))
+			if hashOk {
+				ce.unsignable.add(hash)
+			}
+			ce.reportResult(hash, OutcomeSkipped, err.Error(), "")
+			skipped++
+			continue
+		}
+		if errors.Is(err, ErrEncryptionKey) {
+			// the pks of a delegation never change, so it is not retried
+			delLogger.Warn("skipping the delegation with an invalid finality provider pk",
+				zap.Error(err))
+			if hashOk {
+				ce.unsignable.add(hash)
+			}
+			ce.reportResult(hash, OutcomeSkipped, err.Error(), "")
+			skipped++
+			continue
+		}
+		if errors.Is(err, ErrSupersededCommittee) {
+			ce.metrics.SupersededCommitteeDelegations.Inc()
+			delLogger.Warn("skipping the delegation staked under a superseded covenant committee",
+				zap.String("behavior", ce.config.SupersededCommittee),
+				zap.Error(err))
+			if hashOk && ce.config.SupersededCommittee == covcfg.SupersededCommitteeSkip {
+				ce.unsignable.add(hash)
+			}
+			ce.reportResult(hash, OutcomeSkipped, err.Error(), "")
+			skipped++
+			continue
+		}
+		if errors.Is(err, ErrSlashingTxFeeTooHigh) {
+			delLogger.Warn("skipping the delegation of which the slashing tx fee is too high",
+				zap.Error(err))
+			ce.warnIfQuorumBlocked(btcDel, delLogger)
+			ce.reportResult(hash, OutcomeSkipped, err.Error(), "")
+			skipped++
+			continue
+		}
+		if errors.Is(err, ErrShuttingDown) {
+			return nil, batchCounts{skipped: int(skipped)}, err
+		}
+		if err != nil {
+			delLogger.Debug("failed to sign the delegation", zap.Error(err))
+			if hashOk {
+				ce.backoff.recordFailure(hash, err.Error(), time.Now())
+			}
+			ce.reportResult(hash, OutcomeFailed, err.Error(), "")
+			ce.stats.update(func(s *MetricsSnapshot) {
+				s.Skipped += skipped
+				s.Failed += numDels - skipped
+				s.LastError = err.Error()
+			})
+			return nil, batchCounts{skipped: int(skipped)}, err
+		}
+
+		// 8. collect covenant sigs
+		covenantSigs = append(covenantSigs, covSigs)
+		delLoggers = append(delLoggers, delLogger.With(zap.String("staking_tx_hash", covSigs.StakingTxHash.String())))
+	}
+
+	// all the delegations are filtered out or already have a covenant quorum
+	if len(covenantSigs) == 0 {
+		ce.stats.update(func(s *MetricsSnapshot) { s.Skipped += skipped })
+		return nil, batchCounts{skipped: int(skipped)}, nil
+	}
+
+	// the sigs are discarded if the params changed while signing, and the
+	// delegations are signed again against the new params in a later round
+	if ce.paramsVersion.Load() != paramsVersion {
+		ce.stats.update(func(s *MetricsSnapshot) { s.Skipped += skipped + uint64(len(covenantSigs)) })
+		for _, covSigs := range covenantSigs {
+			ce.reportResult(covSigs.StakingTxHash.String(), OutcomeSkipped, ErrParamsChanged.Error(), "")
+		}
+		return nil, batchCounts{skipped: int(skipped) + len(covenantSigs)}, ErrParamsChanged
+	}
+
+	// 9. submit covenant sigs
+	res, err := ce.submitCovenantSigs(covenantSigs)
+	if errors.Is(err, clientcontroller.ErrDelegationNotFound) {
+		// some of the delegations are withdrawn after being queried, so the
+		// sigs of the remaining ones are submitted again without them
+		var numVanished int
+		covenantSigs, delLoggers, numVanished = ce.removeVanished(covenantSigs, delLoggers)
+		skipped += uint64(numVanished)
+		if len(covenantSigs) == 0 {
+			ce.stats.update(func(s *MetricsSnapshot) { s.Skipped += skipped })
+			return nil, batchCounts{skipped: int(skipped)}, nil
+		}
+		if numVanished > 0 {
+			res, err = ce.submitCovenantSigs(covenantSigs)
+		}
+	}
+	recordClientResult(err)
+	if err != nil {
+		for _, delLogger := range delLoggers {
+			delLogger.Debug("failed to submit covenant signatures", zap.Error(err))
+		}
+		now := time.Now()
+		for _, covSigs := range covenantSigs {
+			ce.backoff.recordFailure(covSigs.StakingTxHash.String(), err.Error(), now)
+			ce.reportResult(covSigs.StakingTxHash.String(), OutcomeFailed, err.Error(), "")
+		}
+		ce.stats.update(func(s *MetricsSnapshot) {
+			s.Skipped += skipped
+			s.Failed += uint64(len(covenantSigs))
+			s.LastError = err.Error()
+		})
+		return nil, batchCounts{skipped: int(skipped)}, err
+	}
+
+	ce.stats.update(func(s *MetricsSnapshot) {
+		s.Skipped += skipped
+		s.Signed += uint64(len(covenantSigs))
+	})
+
+	for i, delLogger := range delLoggers {
+		ce.backoff.recordSuccess(covenantSigs[i].StakingTxHash.String())
+		if ce.config.StuckSubmissionTimeout != 0 {
+			ce.submissions.record(covenantSigs[i].StakingTxHash.String(), res.TxHash, time.Now())
+		}
+		ce.reportResult(covenantSigs[i].StakingTxHash.String(), OutcomeSigned, "", res.TxHash)
+		delLogger.Info("successfully submitted covenant signatures",
+			zap.String("tx_hash", res.TxHash),
+			zap.Int64("height", res.Height),
+			zap.Int64("gas_wanted", res.GasWanted),
+			zap.Int64("gas_used", res.GasUsed),
+		)
+	}
+	ce.logger.Debug("the covenant signatures submission tx result",
+		zap.String("tx_hash", res.TxHash),
+		zap.Uint32("code", res.Code),
+		zap.Any("events", res.Events),
+	)
+
+	submitted := make([]chainhash.Hash, 0, len(covenantSigs))
+	for _, covSigs := range covenantSigs {
+		submitted = append(submitted, covSigs.StakingTxHash)
+	}
+
+	return res, batchCounts{signed: len(covenantSigs), skipped: int(skipped), submitted: submitted}, nil
+}
+
+// AddCovenantSignatureAndWait adds a Covenant signature on the given Bitcoin delegation,
+// submits it to Babylon, and then waits until the signature is included in the
+// delegation or the delegation reaches a covenant quorum. The consumer chain is polled
+// with exponential backoff, and ErrSubmittedUnconfirmed is returned along with the
+// response if neither happens within the given timeout or the configured max attempts.
+// This is mainly aimed at integration tests and smoke checks, which need to assert
+// the full round trip.
+func (ce *CovenantEmulator) AddCovenantSignatureAndWait(btcDel *types.Delegation, timeout time.Duration) (*types.TxResponse, error) {
+	if btcDel == nil {
+		return nil, fmt.Errorf("empty delegation")
+	}
+
+	stakingMsgTx, _, err := bbntypes.NewBTCTxFromHex(btcDel.StakingTxHex)
+	if err != nil {
+		return nil, err
+	}
+	stakingTxHash := stakingMsgTx.TxHash()
+
+	res, err := ce.AddCovenantSignatures([]*types.Delegation{btcDel})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ce.waitForConfirmation(stakingTxHash, timeout, ce.config.ConfirmationMaxAttempts); err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// delegationsToBatches takes a list of delegations and splits them into batches
+func (ce *CovenantEmulator) delegationsToBatches(dels []*types.Delegation) [][]*types.Delegation {
+	batchSize := ce.config.SigsBatchSize
+	batches := make([][]*types.Delegation, 0)
+
+	for i := uint64(0); i < uint64(len(dels)); i += batchSize {
+		end := i + batchSize
+		if end > uint64(len(dels)) {
+			end = uint64(len(dels))
+		}
+		batches = append(batches, dels[i:end])
+	}
+
+	return batches
+}
+
+// newCorrelationID generates a random ID used to correlate the logs of
+// processing a single delegation
+func newCorrelationID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		// this should never happen
+		panic(err)
+	}
+
+	return hex.EncodeToString(id)
+}
+
+// stakingTxHashOf returns the hex staking tx hash of the given delegation
+// and false if the staking tx cannot be decoded
+func stakingTxHashOf(del *types.Delegation) (string, bool) {
+	if del == nil {
+		return "", false
+	}
+	stakingMsgTx, _, err := bbntypes.NewBTCTxFromHex(del.StakingTxHex)
+	if err != nil {
+		return "", false
+	}
+
+	return stakingMsgTx.TxHash().String(), true
+}
+
+// signDelegation validates the given delegation against the given staking params and
+// produces the covenant signatures for it. It returns ErrQuorumAlreadyReached if the
+// delegation already has a covenant quorum.
+// The given logger is used for all the logs of processing this delegation, and
+// every check and signing step is recorded into the given trace if it is not nil.
+// NOTE: all the signature types (staking slashing, unbonding, and unbonding slashing)
+// are always produced because only pending delegations are processed and the consumer
+// chain accepts them atomically in a single MsgAddCovenantSigs. There is no lifecycle
+// state in which only a subset of them is needed from this covenant.
+func (ce *CovenantEmulator) signDelegation(
+	btcDel *types.Delegation,
+	params *types.StakingParams,
+	logger *zap.Logger,
+	trace *DecisionTrace,
+) (*types.CovenantSigs, error) {
+	// 0. nil checks
+	if btcDel == nil {
+		return nil, fmt.Errorf("empty delegation")
+	}
+
+	if btcDel.BtcUndelegation == nil {
+		return nil, fmt.Errorf("empty undelegation")
+	}
+
+	if ce.config.LogDecodedDelegations {
+		logDecodedDelegation(logger, btcDel)
+	}
+
+	err := validatePubKeys(btcDel, params)
+	trace.record("pub_keys", err, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// the staking scripts cannot be built with duplicate covenant pks
+	if dups := duplicateCovenantPks(params.CovenantPks); len(dups) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateCovenantPks, strings.Join(dups, ", "))
+	}
+
+	// 1. the quorum is already achieved, skip sending more sigs
+	quorumDetail := fmt.Sprintf("%d of %d sigs", len(btcDel.CovenantSigs), params.CovenantQuorum)
+	if btcDel.HasCovenantQuorum(params.CovenantQuorum) {
+		trace.record("quorum", ErrQuorumAlreadyReached, quorumDetail)
+		return nil, ErrQuorumAlreadyReached
+	}
+	trace.record("quorum", nil, quorumDetail)
+
+	// 1.1. check the slashing address is on the network of the consumer chain,
+	// which the slashing txs are checked against below
+	btcNet := ce.btcNetOf(params)
+	if params.SlashingAddress == nil {
+		return nil, fmt.Errorf("empty slashing address in the staking params")
+	}
+	if !params.SlashingAddress.IsForNet(btcNet) {
+		return nil, fmt.Errorf("%w: slashing address %s, network %s",
+			ErrNetworkMismatch, params.SlashingAddress.EncodeAddress(), btcNet.Name)
+	}
+
+	// 1.2. check the slashing address is allowed
+	err = ce.checkSlashingAddress(params, logger)
+	trace.record("slashing_address", err, params.SlashingAddress.EncodeAddress())
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. check unbonding time (staking time from unbonding tx) is larger than min unbonding time
+	// which is larger value from:
+	// - MinUnbondingTime
+	// - CheckpointFinalizationTimeout
+	// the unbonding time is committed as a uint16 timelock in the scripts
+	unbondingTime := btcDel.UnbondingTime
+	if unbondingTime > math.MaxUint16 {
+		return nil, fmt.Errorf("unbonding time %d exceeds the maximum timelock %d",
+			unbondingTime, math.MaxUint16)
+	}
+	minUnbondingTime := params.MinimumUnbondingTime()
+	if uint64(unbondingTime) <= minUnbondingTime {
+		return nil, fmt.Errorf("unbonding time %d must be larger than %d",
+			unbondingTime, minUnbondingTime)
+	}
+	trace.record("unbonding_time", nil, fmt.Sprintf("%d > %d", unbondingTime, minUnbondingTime))
+
+	// 3. check staking tx and slashing tx are valid
+	stakingMsgTx, err := ce.decodeTx(btcDel.StakingTxHex)
+	if err != nil {
+		return nil, err
+	}
+	logger = logger.With(zap.String("staking_tx_hash", stakingMsgTx.TxHash().String()))
+	trace.setStakingTxHash(stakingMsgTx.TxHash().String())
+
+	if int(btcDel.StakingOutputIdx) >= len(stakingMsgTx.TxOut) {
+		return nil, fmt.Errorf("%w: staking output index %d, staking tx has %d outputs",
+			ErrInvalidOutputIdx, btcDel.StakingOutputIdx, len(stakingMsgTx.TxOut))
+	}
+
+	slashingTx, err := bstypes.NewBTCSlashingTxFromHex(btcDel.SlashingTxHex)
+	if err != nil {
+		return nil, err
+	}
+
+	slashingMsgTx, err := slashingTx.ToMsgTx()
+	if err != nil {
+		return nil, err
+	}
+
+	if ce.config.DetailedValidation {
+		ce.logSlashingTxBreakdown(params, logger, "staking", stakingMsgTx, btcDel.StakingOutputIdx, slashingMsgTx)
+	}
+
+	err = btcstaking.CheckTransactions(
+		slashingMsgTx,
+		stakingMsgTx,
+		btcDel.StakingOutputIdx,
+		int64(params.MinSlashingTxFeeSat),
+		params.SlashingRate,
+		params.SlashingAddress,
+		btcDel.BtcPk,
+		uint16(unbondingTime),
+		btcNet,
+	)
+	trace.record("staking_txs", err, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid txs in the delegation: %w", err)
+	}
+	err = ce.checkSlashingTxFee(stakingMsgTx, btcDel.StakingOutputIdx, slashingMsgTx)
+	trace.record("staking_slashing_tx_fee", err, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid txs in the delegation: %w", err)
+	}
+
+	// 4. Check unbonding transaction
+	unbondingSlashingMsgTx, err := ce.decodeTx(btcDel.BtcUndelegation.SlashingTxHex)
+	if err != nil {
+		return nil, err
+	}
+
+	unbondingMsgTx, err := ce.decodeTx(btcDel.BtcUndelegation.UnbondingTxHex)
+	if err != nil {
+		return nil, err
+	}
+
+	// the 0th output of the unbonding tx is always the unbonding output
+	if len(unbondingMsgTx.TxOut) == 0 {
+		return nil, fmt.Errorf("%w: the unbonding tx has no outputs", ErrInvalidOutputIdx)
+	}
+
+	// 4.1. optionally check the txs against the BTC consensus rules
+	if ce.config.ConsensusChecks {
+		for _, tx := range []struct {
+			txType string
+			msgTx  *wire.MsgTx
+		}{
+			{"staking", stakingMsgTx},
+			{"staking slashing", slashingMsgTx},
+			{"unbonding", unbondingMsgTx},
+			{"unbonding slashing", unbondingSlashingMsgTx},
+		} {
+			err := checkConsensusRules(logger, tx.txType, tx.msgTx)
+			trace.record("consensus_rules", err, tx.txType)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	stakingInfo, unbondingInfo, err := BuildDelegationScripts(btcDel, params, btcNet)
+	if err != nil {
+		return nil, err
+	}
+
+	// the spend paths used for signing are derived from the script trees built
+	// above, so make sure they are the ones actually committed by the outputs.
+	// As the other inputs of the scripts are taken from the delegation itself,
+	// a different pk script means that it is staked under another committee.
+	if err := checkOutputScript(stakingMsgTx, btcDel.StakingOutputIdx, stakingInfo.StakingOutput); err != nil {
+		if errors.Is(err, errPkScriptMismatch) {
+			err = fmt.Errorf("%w: the staking output does not commit to the current covenant committee and quorum: %w",
+				ErrSupersededCommittee, err)
+			trace.record("committee", err, "")
+			return nil, err
+		}
+		return nil, fmt.Errorf("the staking output does not match the delegation: %w", err)
+	}
+	trace.record("committee", nil, "")
+
+	if err := checkOutputScript(unbondingMsgTx, 0, unbondingInfo.UnbondingOutput); err != nil {
+		return nil, fmt.Errorf("the unbonding output does not match the delegation: %w", err)
+	}
+	trace.record("output_scripts", nil, "")
+
+	if ce.config.DetailedValidation {
+		ce.logSlashingTxBreakdown(params, logger, "unbonding", unbondingMsgTx, 0, unbondingSlashingMsgTx)
+	}
+
+	err = btcstaking.CheckTransactions(
+		unbondingSlashingMsgTx,
+		unbondingMsgTx,
+		0,
+		int64(params.MinSlashingTxFeeSat),
+		params.SlashingRate,
+		params.SlashingAddress,
+		btcDel.BtcPk,
+		uint16(unbondingTime),
+		btcNet,
+	)
+	trace.record("undelegation_txs", err, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid txs in the undelegation: %w", err)
+	}
+	err = ce.checkSlashingTxFee(unbondingMsgTx, 0, unbondingSlashingMsgTx)
+	trace.record("unbonding_slashing_tx_fee", err, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid txs in the undelegation: %w", err)
+	}
+
+	logger.Debug("the delegation txs are valid")
+
+	// the babylon signing functions sign with SIGHASH_DEFAULT, which is the
+	// only sighash type accepted by the config validation
+	logger.Debug("signing the delegation", zap.String("sighash_type", ce.config.SigHashType))
+
+	// 5. sign covenant staking sigs
+	covenantPrivKey, err := ce.getPrivKey(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	slashingPathInfo, err := ce.spendPathSelector.StakingSlashingPath(btcDel, stakingInfo)
+	if err == nil {
+		err = checkSpendPath(slashingPathInfo, stakingInfo.StakingOutput)
+	}
+	trace.record("staking_slashing_path", err, "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: no slashing path in the staking output: %w", ErrUnsignableDelegation, err)
+	}
+
+	encKeys, err := ce.deriveEncKeys(btcDel.FpBtcPks)
+	if err != nil {
+		return nil, err
+	}
+
+	// numSigs counts the adaptor sigs computed for yielding
+	var numSigs uint32
+	covSigs := make([][]byte, 0, len(btcDel.FpBtcPks))
+	for i, valPk := range btcDel.FpBtcPks {
+		encKey := encKeys[i]
+		covenantSig, err := slashingTx.EncSign(
+			stakingMsgTx,
+			btcDel.StakingOutputIdx,
+			slashingPathInfo.GetPkScriptPath(),
+			covenantPrivKey,
+			encKey,
+		)
+		if err != nil {
+			return nil, err
+		}
+		// verify the adaptor sig can be decrypted by the finality provider into
+		// a valid Schnorr sig, which catches encryption key or path mismatches
+		if err := slashingTx.EncVerifyAdaptorSignature(
+			stakingInfo.StakingOutput.PkScript,
+			stakingInfo.StakingOutput.Value,
+			slashingPathInfo.GetPkScriptPath(),
+			covenantPrivKey.PubKey(),
+			encKey,
+			covenantSig,
+		); err != nil {
+			return nil, fmt.Errorf("invalid staking slashing adaptor sig for finality provider %s: %w",
+				hex.EncodeToString(schnorr.SerializePubKey(valPk)), err)
+		}
+		covSigs = append(covSigs, covenantSig.MustMarshal())
+		trace.record("staking_slashing_sig", nil, hex.EncodeToString(schnorr.SerializePubKey(valPk)))
+		numSigs++
+		if err := ce.yieldIfDue(numSigs); err != nil {
+			return nil, err
+		}
+	}
+
+	// 6. sign covenant unbonding sig
+	stakingTxUnbondingPathInfo, err := ce.spendPathSelector.StakingUnbondingPath(btcDel, stakingInfo)
+	if err == nil {
+		err = checkSpendPath(stakingTxUnbondingPathInfo, stakingInfo.StakingOutput)
+	}
+	trace.record("staking_unbonding_path", err, "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: no unbonding path in the staking output: %w", ErrUnsignableDelegation, err)
+	}
+	covenantUnbondingSignature, err := btcstaking.SignTxWithOneScriptSpendInputStrict(
+		unbondingMsgTx,
+		stakingMsgTx,
+		btcDel.StakingOutputIdx,
+		stakingTxUnbondingPathInfo.GetPkScriptPath(),
+		covenantPrivKey,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign unbonding tx: %w", err)
+	}
+	trace.record("unbonding_sig", nil, "")
+
+	// 7. sign covenant unbonding slashing sig
+	slashUnbondingTx, err := bstypes.NewBTCSlashingTxFromHex(btcDel.BtcUndelegation.SlashingTxHex)
+	if err != nil {
+		return nil, err
+	}
+
+	unbondingTxSlashingPath, err := ce.spendPathSelector.UnbondingSlashingPath(btcDel, unbondingInfo)
+	if err == nil {
+		err = checkSpendPath(unbondingTxSlashingPath, unbondingInfo.UnbondingOutput)
+	}
+	trace.record("unbonding_slashing_path", err, "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: no slashing path in the unbonding output: %w", ErrUnsignableDelegation, err)
+	}
+
+	covSlashingSigs := make([][]byte, 0, len(btcDel.FpBtcPks))
+	for i, fpPk := range btcDel.FpBtcPks {
+		encKey := encKeys[i]
+		covenantSig, err := slashUnbondingTx.EncSign(
+			unbondingMsgTx,
+			0, // 0th output is always the unbonding script output
+			unbondingTxSlashingPath.GetPkScriptPath(),
+			covenantPrivKey,
+			encKey,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := slashUnbondingTx.EncVerifyAdaptorSignature(
+			unbondingInfo.UnbondingOutput.PkScript,
+			unbondingInfo.UnbondingOutput.Value,
+			unbondingTxSlashingPath.GetPkScriptPath(),
+			covenantPrivKey.PubKey(),
+			encKey,
+			covenantSig,
+		); err != nil {
+			return nil, fmt.Errorf("invalid unbonding slashing adaptor sig for finality provider %s: %w",
+				hex.EncodeToString(schnorr.SerializePubKey(fpPk)), err)
+		}
+		covSlashingSigs = append(covSlashingSigs, covenantSig.MustMarshal())
+		trace.record("unbonding_slashing_sig", nil, hex.EncodeToString(schnorr.SerializePubKey(fpPk)))
+		numSigs++
+		if err := ce.yieldIfDue(numSigs); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Debug("signed the delegation")
+
+	return &types.CovenantSigs{
+		PublicKey:             ce.pk,
+		StakingTxHash:         stakingMsgTx.TxHash(),
+		SlashingSigs:          covSigs,
+		UnbondingSig:          covenantUnbondingSignature,
+		SlashingUnbondingSigs: covSlashingSigs,
+	}, nil
+}
+
+// yieldIfDue checks for shutdown and yields the processor after every
+// configured number of computed sigs so that signing a delegation to many
+// finality providers neither delays Stop nor monopolizes the CPU
+func (ce *CovenantEmulator) yieldIfDue(numSigs uint32) error {
+	n := ce.config.MaxSigsBeforeYield
+	if n == 0 || numSigs%n != 0 {
+		return nil
+	}
+
+	select {
+	case <-ce.quit:
+		return ErrShuttingDown
+	default:
+	}
+	runtime.Gosched()
+
+	return nil
+}
+
+// checkSlashingAddress checks that the slashing address in the staking params
+// is in the configured allowlist, if any. This protects against governance
+// changes that redirect the slashing funds.
+func (ce *CovenantEmulator) checkSlashingAddress(params *types.StakingParams, logger *zap.Logger) error {
+	allowed := ce.config.AllowedSlashingAddresses
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	slashingAddr := params.SlashingAddress.EncodeAddress()
+	for _, addr := range allowed {
+		if addr == slashingAddr {
+			return nil
+		}
+	}
+
+	logger.Error("refusing to sign as the slashing address in the staking params is not allowed, "+
+		"check whether the staking params are changed as expected",
+		zap.String("slashing_address", slashingAddr),
+		zap.Strings("allowed_slashing_addresses", allowed),
+	)
+
+	return fmt.Errorf("%w: %s", ErrSlashingAddressNotAllowed, slashingAddr)
+}
+
+// checkSlashingTxFee checks that the fee of the given slashing tx, which spends
+// the given output of the funding tx, does not exceed the configured maximum.
+// CheckTransactions only enforces the minimum fee of the staking params.
+func (ce *CovenantEmulator) checkSlashingTxFee(fundingTx *wire.MsgTx, fundingOutputIdx uint32, slashingTx *wire.MsgTx) error {
+	maxFee := ce.config.MaxSlashingTxFeeSat
+	if maxFee == 0 {
+		return nil
+	}
+
+	fee := fundingTx.TxOut[fundingOutputIdx].Value
+	for _, out := range slashingTx.TxOut {
+		fee -= out.Value
+	}
+	if fee > 0 && uint64(fee) > maxFee {
+		return fmt.Errorf("%w: fee %d, max fee %d", ErrSlashingTxFeeTooHigh, fee, maxFee)
+	}
+
+	return nil
+}
+
+// logSlashingTxBreakdown logs the amounts derived from the given slashing tx
+// and the output it spends so that operators can audit them before signing.
+// The slashing output is expected to be the 0th output and the change output
+// to be the 1st one, as enforced by CheckTransactions
+func (ce *CovenantEmulator) logSlashingTxBreakdown(
+	params *types.StakingParams,
+	logger *zap.Logger,
+	txType string,
+	fundingTx *wire.MsgTx,
+	fundingOutputIdx uint32,
+	slashingTx *wire.MsgTx,
+) {
+	if int(fundingOutputIdx) >= len(fundingTx.TxOut) || len(slashingTx.TxOut) < 2 {
+		logger.Debug("unable to derive the slashing breakdown due to malformed txs",
+			zap.String("tx_type", txType))
+		return
+	}
+
+	fundingValue := fundingTx.TxOut[fundingOutputIdx].Value
+	expectedSlashingAmount := params.SlashingRate.MulInt64(fundingValue).TruncateInt64()
+	slashingOutputValue := slashingTx.TxOut[0].Value
+	changeValue := slashingTx.TxOut[1].Value
+	var totalOutputValue int64
+	for _, out := range slashingTx.TxOut {
+		totalOutputValue += out.Value
+	}
+
+	logger.Debug("slashing tx breakdown",
+		zap.String("tx_type", txType),
+		zap.String("funding_tx_hash", fundingTx.TxHash().String()),
+		zap.Int64("funding_value", fundingValue),
+		zap.String("slashing_rate", params.SlashingRate.String()),
+		zap.Int64("expected_slashing_amount", expectedSlashingAmount),
+		zap.Int64("slashing_output_value", slashingOutputValue),
+		zap.Int64("change_value", changeValue),
+		zap.Int64("burn_amount", fundingValue-changeValue),
+		zap.Int64("fee", fundingValue-totalOutputValue),
+		zap.Int64("min_fee", int64(params.MinSlashingTxFeeSat)),
+	)
+}
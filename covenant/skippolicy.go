@@ -0,0 +1,80 @@
+package covenant
+
+import (
+	"bytes"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// skippingPolicies returns the configured policies under which the emulator
+// refuses to sign some delegations that the consumer chain accepts
+func (ce *CovenantEmulator) skippingPolicies() []string {
+	var policies []string
+	if ce.config.MinStakingTime != 0 || ce.config.MaxStakingTime != 0 {
+		policies = append(policies, "staking time range")
+	}
+	if ce.config.MaxSlashingTxFeeSat != 0 {
+		policies = append(policies, "max slashing tx fee")
+	}
+	if len(ce.config.AllowedSlashingAddresses) != 0 {
+		policies = append(policies, "allowed slashing addresses")
+	}
+
+	return policies
+}
+
+// quorumReachableWithoutUs returns whether the other members of the covenant
+// committee can reach the quorum on their own under the given params
+func (ce *CovenantEmulator) quorumReachableWithoutUs(params *types.StakingParams) bool {
+	committeeSize := len(params.CovenantPks) - len(duplicateCovenantPks(params.CovenantPks))
+	others := committeeSize
+	for _, covPk := range params.CovenantPks {
+		if bytes.Equal(schnorr.SerializePubKey(covPk), schnorr.SerializePubKey(ce.pk)) {
+			others--
+			break
+		}
+	}
+
+	return others >= int(params.CovenantQuorum)
+}
+
+// checkSkippingPolicies warns if the configured policies can make the
+// covenant quorum unreachable under the current staking params, i.e., the
+// delegations that we skip are never activated
+func (ce *CovenantEmulator) checkSkippingPolicies() {
+	params := ce.params.Load()
+	if params == nil || ce.quorumReachableWithoutUs(params) {
+		return
+	}
+
+	if policies := ce.skippingPolicies(); len(policies) != 0 {
+		ce.logger.Warn("the covenant quorum cannot be reached without our signature, "+
+			"the delegations skipped by the configured policies are never activated",
+			zap.Strings("policies", policies),
+			zap.Uint32("quorum", params.CovenantQuorum),
+			zap.Int("committee_size", len(params.CovenantPks)),
+		)
+	}
+	if ce.config.OnlyDecidingSig && int(params.CovenantQuorum) == len(params.CovenantPks) {
+		ce.logger.Warn("the covenant quorum equals the committee size while only signing deciding sigs, " +
+			"delegations are never activated if another member also defers its signature")
+	}
+}
+
+// warnIfQuorumBlocked warns if skipping the given delegation makes the covenant
+// quorum unreachable for it, which is called upon a skip by a policy
+func (ce *CovenantEmulator) warnIfQuorumBlocked(btcDel *types.Delegation, logger *zap.Logger) {
+	params := ce.params.Load()
+	if btcDel == nil || params == nil || ce.quorumReachableWithoutUs(params) {
+		return
+	}
+
+	logger.Warn("skipping the delegation makes the covenant quorum unreachable, "+
+		"it is never activated unless the policy is changed",
+		zap.Int("covenant_sigs", len(btcDel.CovenantSigs)),
+		zap.Uint32("quorum", params.CovenantQuorum),
+	)
+}
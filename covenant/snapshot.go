@@ -0,0 +1,45 @@
+package covenant
+
+import (
+	"sync"
+	"time"
+)
+
+// MetricsSnapshot is a point-in-time copy of the signing counters of the emulator
+type MetricsSnapshot struct {
+	// Signed is the number of delegations of which the covenant signatures are submitted
+	Signed uint64 `json:"signed"`
+	// Failed is the number of delegations that failed to be signed or submitted
+	Failed uint64 `json:"failed"`
+	// Skipped is the number of delegations that are skipped because they
+	// already have a covenant quorum or are filtered out by the config
+	Skipped uint64 `json:"skipped"`
+	// Vanished is the number of skipped delegations that no longer exist on
+	// the consumer chain when their sigs are submitted
+	Vanished uint64 `json:"vanished"`
+	// InFlight is the number of delegations that are being signed or submitted
+	InFlight uint64 `json:"in_flight"`
+	// LastError is the last error of signing or submitting delegations
+	LastError string `json:"last_error,omitempty"`
+	// LastLoopTime is the time at which the last round of the submission loop finished
+	LastLoopTime time.Time `json:"last_loop_time"`
+}
+
+// signingStats holds the counters behind MetricsSnapshot
+type signingStats struct {
+	mu       sync.Mutex
+	snapshot MetricsSnapshot
+}
+
+func (s *signingStats) update(f func(snapshot *MetricsSnapshot)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f(&s.snapshot)
+}
+
+// Metrics returns a snapshot of the signing counters of the emulator
+func (ce *CovenantEmulator) Metrics() MetricsSnapshot {
+	ce.stats.mu.Lock()
+	defer ce.stats.mu.Unlock()
+	return ce.stats.snapshot
+}
@@ -0,0 +1,73 @@
+package covenant_test
+
+import (
+	"math/rand"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/covenant-emulator/testutil"
+	"github.com/babylonchain/covenant-emulator/testutil/fakeclient"
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// soakDurationEnv is the environment variable which enables the soak test
+// with the given duration, e.g., COVENANT_SOAK_DURATION=10m
+const soakDurationEnv = "COVENANT_SOAK_DURATION"
+
+// TestSoak repeatedly signs synthetic delegations through the full signing
+// pipeline against an in-memory consumer chain for the configured duration
+// and reports the throughput, latency percentiles, and errors
+func TestSoak(t *testing.T) {
+	durationStr := os.Getenv(soakDurationEnv)
+	if durationStr == "" {
+		t.Skipf("set %s to run the soak test", soakDurationEnv)
+	}
+	duration, err := time.ParseDuration(durationStr)
+	require.NoError(t, err)
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	params := testutil.GenRandomParams(r, t)
+	fc := fakeclient.New(params)
+	ce := newTestEmulator(t, fc)
+
+	var (
+		latencies []time.Duration
+		numErrs   int
+	)
+	deadline := time.Now().Add(duration)
+	for time.Now().Before(deadline) {
+		fpNum := int(r.Int31n(5)) + 1
+		td := genTestDelegation(t, r, params, fpNum)
+		require.NoError(t, fc.AddPendingDelegations(td.del))
+
+		start := time.Now()
+		_, err := ce.AddCovenantSignatures([]*types.Delegation{td.del})
+		latencies = append(latencies, time.Since(start))
+		if err != nil {
+			numErrs++
+			t.Logf("failed to sign delegation: %v", err)
+		}
+	}
+
+	require.NotEmpty(t, latencies)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	percentile := func(p float64) time.Duration {
+		return latencies[int(p*float64(len(latencies)-1))]
+	}
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+
+	t.Logf("signed %d delegations in %v with %d errors", len(latencies), duration, numErrs)
+	t.Logf("throughput: %.2f delegations/s of signing time",
+		float64(len(latencies))/total.Seconds())
+	t.Logf("latency: p50 %v, p90 %v, p99 %v, max %v",
+		percentile(0.5), percentile(0.9), percentile(0.99), latencies[len(latencies)-1])
+	t.Logf("metrics: %+v", ce.Metrics())
+	require.Zero(t, numErrs)
+}
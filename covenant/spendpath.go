@@ -0,0 +1,74 @@
+package covenant
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/babylonchain/babylon/btcstaking"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// SpendPathSelector selects the spend paths of the script trees of a delegation
+// which the covenant signs, so that a delegation type with other spend paths
+// can be signed without changing the signing itself. The delegation is passed
+// so that the selection can depend on its type.
+type SpendPathSelector interface {
+	// StakingSlashingPath selects the path of the staking output spent by the
+	// slashing tx
+	StakingSlashingPath(btcDel *types.Delegation, stakingInfo *btcstaking.StakingInfo) (*btcstaking.SpendInfo, error)
+	// StakingUnbondingPath selects the path of the staking output spent by the
+	// unbonding tx
+	StakingUnbondingPath(btcDel *types.Delegation, stakingInfo *btcstaking.StakingInfo) (*btcstaking.SpendInfo, error)
+	// UnbondingSlashingPath selects the path of the unbonding output spent by
+	// the unbonding slashing tx
+	UnbondingSlashingPath(btcDel *types.Delegation, unbondingInfo *btcstaking.UnbondingInfo) (*btcstaking.SpendInfo, error)
+}
+
+// stakingSpendPathSelector selects the slashing and unbonding paths of the
+// staking scripts, as in the current protocol version
+type stakingSpendPathSelector struct{}
+
+// DefaultSpendPathSelector is the spend path selection used unless another
+// one is set via SetSpendPathSelector
+var DefaultSpendPathSelector SpendPathSelector = stakingSpendPathSelector{}
+
+func (stakingSpendPathSelector) StakingSlashingPath(_ *types.Delegation, stakingInfo *btcstaking.StakingInfo) (*btcstaking.SpendInfo, error) {
+	return stakingInfo.SlashingPathSpendInfo()
+}
+
+func (stakingSpendPathSelector) StakingUnbondingPath(_ *types.Delegation, stakingInfo *btcstaking.StakingInfo) (*btcstaking.SpendInfo, error) {
+	return stakingInfo.UnbondingPathSpendInfo()
+}
+
+func (stakingSpendPathSelector) UnbondingSlashingPath(_ *types.Delegation, unbondingInfo *btcstaking.UnbondingInfo) (*btcstaking.SpendInfo, error) {
+	return unbondingInfo.SlashingPathSpendInfo()
+}
+
+// SetSpendPathSelector sets the selection of the spend paths signed for the
+// delegations. It must be called before the emulator is started.
+func (ce *CovenantEmulator) SetSpendPathSelector(selector SpendPathSelector) {
+	ce.spendPathSelector = selector
+}
+
+// checkSpendPath checks that the given output commits to the script tree of
+// which the given spend path is a leaf, i.e., the path can spend the output
+func checkSpendPath(spendInfo *btcstaking.SpendInfo, output *wire.TxOut) error {
+	if spendInfo == nil {
+		return fmt.Errorf("%w: empty spend path", ErrSpendPathMismatch)
+	}
+
+	rootHash := spendInfo.ControlBlock.RootHash(spendInfo.RevealedLeaf.Script)
+	outputKey := txscript.ComputeTaprootOutputKey(spendInfo.ControlBlock.InternalKey, rootHash)
+	pkScript, err := txscript.PayToTaprootScript(outputKey)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSpendPathMismatch, err)
+	}
+	if !bytes.Equal(pkScript, output.PkScript) {
+		return ErrSpendPathMismatch
+	}
+
+	return nil
+}
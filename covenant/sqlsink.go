@@ -0,0 +1,82 @@
+package covenant
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+const (
+	createResultsTableQuery = `CREATE TABLE IF NOT EXISTS delegation_results (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	staking_tx_hash TEXT NOT NULL,
+	outcome TEXT NOT NULL,
+	reason TEXT NOT NULL,
+	tx_hash TEXT NOT NULL,
+	time TIMESTAMP NOT NULL
+)`
+	createResultsIndexQuery = `CREATE INDEX IF NOT EXISTS delegation_results_staking_tx_hash
+	ON delegation_results (staking_tx_hash)`
+	insertResultQuery = `INSERT INTO delegation_results
+	(staking_tx_hash, outcome, reason, tx_hash, time) VALUES (?, ?, ?, ?, ?)`
+)
+
+// SQLResultSink is the ResultSink which records the results into the
+// delegation_results table of a SQL database with the SQLite dialect. The
+// driver is not imported by this package so that the emulator does not depend
+// on CGo, and must be linked into the binary, e.g., with
+//
+//	import _ "github.com/mattn/go-sqlite3"
+type SQLResultSink struct {
+	db     *sql.DB
+	insert *sql.Stmt
+}
+
+// NewSQLResultSink opens the database of the given driver and data source
+// name and creates the results table if it does not exist
+func NewSQLResultSink(driver, dsn string) (*SQLResultSink, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the results database: %w", err)
+	}
+
+	for _, query := range []string{createResultsTableQuery, createResultsIndexQuery} {
+		if _, err := db.Exec(query); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create the results table: %w", err)
+		}
+	}
+
+	insert, err := db.Prepare(insertResultQuery)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to prepare the results insertion: %w", err)
+	}
+
+	return &SQLResultSink{db: db, insert: insert}, nil
+}
+
+// Record inserts the result as a row of the results table
+func (s *SQLResultSink) Record(result DelegationResult) error {
+	_, err := s.insert.Exec(
+		result.StakingTxHash,
+		string(result.Outcome),
+		result.Reason,
+		result.TxHash,
+		result.Time.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert the delegation result: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the database
+func (s *SQLResultSink) Close() error {
+	if err := s.insert.Close(); err != nil {
+		s.db.Close()
+		return err
+	}
+
+	return s.db.Close()
+}
@@ -0,0 +1,118 @@
+package covenant
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+const (
+	// RoleActive denotes an emulator that submits covenant signatures
+	RoleActive = "active"
+	// RoleStandby denotes an emulator that computes but does not submit covenant signatures
+	RoleStandby = "standby"
+)
+
+// standbySigs keeps the covenant signatures precomputed by a standby emulator
+// for the delegations that are pending in its last round
+type standbySigs struct {
+	mu   sync.Mutex
+	sigs map[chainhash.Hash]*types.CovenantSigs
+}
+
+// replace replaces the precomputed signatures so that only the ones of the
+// currently pending delegations are kept
+func (s *standbySigs) replace(sigs map[chainhash.Hash]*types.CovenantSigs) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sigs = sigs
+}
+
+// take returns and clears the precomputed signatures
+func (s *standbySigs) take() []*types.CovenantSigs {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sigs := make([]*types.CovenantSigs, 0, len(s.sigs))
+	for _, covSigs := range s.sigs {
+		sigs = append(sigs, covSigs)
+	}
+	s.sigs = nil
+
+	return sigs
+}
+
+// Role returns whether the emulator is active or standby
+func (ce *CovenantEmulator) Role() string {
+	if ce.standby.Load() {
+		return RoleStandby
+	}
+
+	return RoleActive
+}
+
+// precomputeSigs validates and signs the given delegations without submitting
+// the signatures, which are kept for when the emulator is promoted
+func (ce *CovenantEmulator) precomputeSigs(dels []*types.Delegation) error {
+	params := ce.params.Load()
+	sigs := make(map[chainhash.Hash]*types.CovenantSigs, len(dels))
+	for _, btcDel := range dels {
+		delLogger := ce.logger.With(zap.String("correlation_id", newCorrelationID()))
+		if ok, _ := ce.acceptDelegation(btcDel, params, delLogger); !ok {
+			continue
+		}
+		covSigs, err := ce.signDelegation(btcDel, params, delLogger, nil)
+		if err != nil {
+			delLogger.Debug("standby failed to sign the delegation", zap.Error(err))
+			if errors.Is(err, ErrKeyringUnlock) {
+				return err
+			}
+			continue
+		}
+		sigs[covSigs.StakingTxHash] = covSigs
+	}
+	ce.standbySigs.replace(sigs)
+
+	ce.logger.Debug("standby precomputed covenant signatures", zap.Int("num_delegations", len(sigs)))
+
+	return nil
+}
+
+// Promote switches a standby emulator to active and immediately submits the
+// precomputed signatures. Signatures that are rejected, e.g., because the
+// delegations are signed in the meantime, are recomputed in later rounds.
+func (ce *CovenantEmulator) Promote() error {
+	if !ce.standby.CompareAndSwap(true, false) {
+		return nil
+	}
+	ce.metrics.Standby.Set(0)
+	ce.logger.Info("the covenant emulator is promoted to active")
+
+	ctx, cancel := ce.quitContext()
+	defer cancel()
+
+	sigs := ce.standbySigs.take()
+	batchSize := int(ce.config.SigsBatchSize)
+	for i := 0; i < len(sigs); i += batchSize {
+		end := i + batchSize
+		if end > len(sigs) {
+			end = len(sigs)
+		}
+		res, err := ce.cc.SubmitCovenantSigs(ctx, sigs[i:end])
+		ce.recordClientResult(err)
+		if err != nil {
+			return fmt.Errorf("failed to submit the precomputed covenant signatures: %w", err)
+		}
+		ce.logger.Info("submitted the precomputed covenant signatures",
+			zap.Int("num_delegations", end-i),
+			zap.String("tx_hash", res.TxHash),
+		)
+	}
+
+	return nil
+}
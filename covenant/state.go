@@ -0,0 +1,142 @@
+package covenant
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// stateVersion is the version of the format of the exported state. Fields
+// may be added without bumping it, which the importers of older releases
+// ignore, while it is bumped upon incompatible changes so that older
+// releases refuse to import the state rather than misread it.
+const stateVersion = 1
+
+// exportedState is the internal state of the emulator which is carried over
+// to a new instance upon a hot migration. Apart from the cursor, it is kept
+// per delegation and does not grow with the historical delegations. The
+// unsignable and reorged delegations are pruned once a full sweep of the
+// pending pages no longer returns them. A submission is forgotten once its
+// delegation is signed by us, reaches the covenant quorum or no longer exists,
+// or once it reaches the max age. A backoff expires once the delegation is not
+// attempted for the max retry interval after it elapses.
+type exportedState struct {
+	Version uint32 `json:"version"`
+	// PageKey is the page of pending delegations to query next
+	PageKey []byte `json:"page_key,omitempty"`
+	// Backoff is the backoff of the failed delegations by staking tx hash
+	Backoff map[string]backoffState `json:"backoff,omitempty"`
+	// Unsignable are the staking tx hashes of the delegations skipped for good
+	Unsignable []string `json:"unsignable,omitempty"`
+	// Submissions are the submissions of which the sigs are not yet
+	// included in the delegations by staking tx hash
+	Submissions map[string]submissionState `json:"submissions,omitempty"`
+	// Reorged are the staking tx hashes of the delegations found reorged out
+	Reorged []string `json:"reorged,omitempty"`
+}
+
+type backoffState struct {
+	LastAttempt time.Time     `json:"last_attempt"`
+	Interval    time.Duration `json:"interval"`
+	Cause       string        `json:"cause"`
+}
+
+type submissionState struct {
+	TxHash      string    `json:"tx_hash"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	Reported    bool      `json:"reported,omitempty"`
+}
+
+// ExportState serializes the internal state of the emulator, i.e., the cursor
+// of pending delegations, the backoff of failed delegations, the delegations
+// skipped for good, and the submissions awaiting inclusion, so that a new
+// instance on another host resumes where this one left off after ImportState.
+// The sigs precomputed by a standby are not exported.
+func (ce *CovenantEmulator) ExportState() ([]byte, error) {
+	state := exportedState{
+		Version:     stateVersion,
+		PageKey:     ce.cursor.get(),
+		Backoff:     make(map[string]backoffState),
+		Submissions: make(map[string]submissionState),
+		Unsignable:  ce.unsignable.list(),
+		Reorged:     ce.reorged.list(),
+	}
+
+	ce.backoff.mu.Lock()
+	for hash, entry := range ce.backoff.entries {
+		state.Backoff[hash] = backoffState{
+			LastAttempt: entry.lastAttempt,
+			Interval:    entry.interval,
+			Cause:       entry.cause,
+		}
+	}
+	ce.backoff.mu.Unlock()
+
+	ce.submissions.mu.Lock()
+	for hash, sub := range ce.submissions.entries {
+		state.Submissions[hash] = submissionState{
+			TxHash:      sub.txHash,
+			SubmittedAt: sub.submittedAt,
+			Reported:    sub.reported,
+		}
+	}
+	ce.submissions.mu.Unlock()
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the state: %w", err)
+	}
+
+	return data, nil
+}
+
+// ImportState replaces the internal state of the emulator with the one
+// exported by ExportState of another instance. It must be called before the
+// emulator is started. A state of a newer incompatible version is rejected.
+func (ce *CovenantEmulator) ImportState(data []byte) error {
+	var state exportedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to decode the state: %w", err)
+	}
+	if state.Version == 0 || state.Version > stateVersion {
+		return fmt.Errorf("unsupported state version %d, expected at most %d", state.Version, stateVersion)
+	}
+
+	ce.cursor.set(state.PageKey)
+	ce.unsignable.replace(state.Unsignable)
+	ce.reorged.replace(state.Reorged)
+
+	ce.backoff.mu.Lock()
+	ce.backoff.entries = make(map[string]*backoffEntry, len(state.Backoff))
+	for hash, entry := range state.Backoff {
+		ce.backoff.entries[hash] = &backoffEntry{
+			lastAttempt: entry.LastAttempt,
+			interval:    entry.Interval,
+			cause:       entry.Cause,
+		}
+	}
+	ce.backoff.mu.Unlock()
+
+	ce.submissions.mu.Lock()
+	ce.submissions.entries = make(map[string]*submission, len(state.Submissions))
+	for hash, sub := range state.Submissions {
+		ce.submissions.entries[hash] = &submission{
+			txHash:      sub.TxHash,
+			submittedAt: sub.SubmittedAt,
+			reported:    sub.Reported,
+		}
+	}
+	ce.submissions.mu.Unlock()
+
+	ce.logger.Info("imported the state",
+		zap.Uint32("version", state.Version),
+		zap.Int("backoff", len(state.Backoff)),
+		zap.Int("unsignable", len(state.Unsignable)),
+		zap.Int("submissions", len(state.Submissions)),
+		zap.Int("reorged", len(state.Reorged)),
+	)
+
+	return nil
+}
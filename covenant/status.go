@@ -0,0 +1,18 @@
+package covenant
+
+// Status describes the current state of the emulator
+type Status struct {
+	// BreakerState is the state of the circuit breaker around the consumer
+	// chain client, one of "closed", "open", and "half-open"
+	BreakerState string `json:"breaker_state"`
+	// Role is either "active" or "standby"
+	Role string `json:"role"`
+}
+
+// Status returns the current state of the emulator
+func (ce *CovenantEmulator) Status() Status {
+	return Status{
+		BreakerState: ce.breaker.getState().String(),
+		Role:         ce.Role(),
+	}
+}
@@ -0,0 +1,62 @@
+package covenant
+
+import (
+	"fmt"
+
+	bbntypes "github.com/babylonchain/babylon/types"
+	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// ValidateDelegationStructure checks the structural invariants of the given
+// delegation which do not depend on the staking params, i.e., that it has an
+// undelegation, finality providers, decodable txs and pks, and an in-range
+// staking output index. It is a cheap pre-filter which can run before the
+// params are queried. A malformed delegation fails with ErrMalformedDelegation,
+// while passing it does not imply that the delegation can be signed.
+func ValidateDelegationStructure(btcDel *types.Delegation) error {
+	if btcDel == nil {
+		return fmt.Errorf("%w: empty delegation", ErrMalformedDelegation)
+	}
+	if btcDel.BtcUndelegation == nil {
+		return fmt.Errorf("%w: empty undelegation", ErrMalformedDelegation)
+	}
+
+	if err := validateBIP340PubKey(btcDel.BtcPk); err != nil {
+		return fmt.Errorf("%w: invalid staker pk: %w", ErrMalformedDelegation, err)
+	}
+	if len(btcDel.FpBtcPks) == 0 {
+		return fmt.Errorf("%w: empty finality provider pks", ErrMalformedDelegation)
+	}
+	for i, fpPk := range btcDel.FpBtcPks {
+		if err := validateBIP340PubKey(fpPk); err != nil {
+			return fmt.Errorf("%w: invalid finality provider pk at index %d: %w", ErrMalformedDelegation, i, err)
+		}
+	}
+
+	stakingMsgTx, _, err := bbntypes.NewBTCTxFromHex(btcDel.StakingTxHex)
+	if err != nil {
+		return fmt.Errorf("%w: invalid staking tx: %w", ErrMalformedDelegation, err)
+	}
+	if int(btcDel.StakingOutputIdx) >= len(stakingMsgTx.TxOut) {
+		return fmt.Errorf("%w: %w: staking output index %d, staking tx has %d outputs",
+			ErrMalformedDelegation, ErrInvalidOutputIdx, btcDel.StakingOutputIdx, len(stakingMsgTx.TxOut))
+	}
+	if _, err := bstypes.NewBTCSlashingTxFromHex(btcDel.SlashingTxHex); err != nil {
+		return fmt.Errorf("%w: invalid slashing tx: %w", ErrMalformedDelegation, err)
+	}
+
+	unbondingMsgTx, _, err := bbntypes.NewBTCTxFromHex(btcDel.BtcUndelegation.UnbondingTxHex)
+	if err != nil {
+		return fmt.Errorf("%w: invalid unbonding tx: %w", ErrMalformedDelegation, err)
+	}
+	if len(unbondingMsgTx.TxOut) == 0 {
+		return fmt.Errorf("%w: %w: the unbonding tx has no outputs", ErrMalformedDelegation, ErrInvalidOutputIdx)
+	}
+	if _, err := bstypes.NewBTCSlashingTxFromHex(btcDel.BtcUndelegation.SlashingTxHex); err != nil {
+		return fmt.Errorf("%w: invalid unbonding slashing tx: %w", ErrMalformedDelegation, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package covenant
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/clientcontroller"
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// submission is a tx submitting our sigs of a delegation
+type submission struct {
+	txHash      string
+	submittedAt time.Time
+	// reported is set once the submission is reported as stuck
+	reported bool
+}
+
+// submissionTracker keeps the submissions of which the sigs are not yet
+// included in the pending delegations, by staking tx hash
+type submissionTracker struct {
+	mu      sync.Mutex
+	entries map[string]*submission
+}
+
+func (s *submissionTracker) record(stakingTxHash, txHash string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entries == nil {
+		s.entries = make(map[string]*submission)
+	}
+	s.entries[stakingTxHash] = &submission{txHash: txHash, submittedAt: now}
+}
+
+func (s *submissionTracker) forget(stakingTxHash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, stakingTxHash)
+}
+
+// forgetSettledSubmissions forgets the submissions of which the delegations
+// are signed by us or reach the covenant quorum, and those of which the
+// delegations no longer exist on the consumer chain. The given delegations are
+// a page of the pending ones, so the delegations of the other submissions,
+// e.g., on other pages, are queried rather than taken as settled. The
+// submissions reaching the configured max age are forgotten as well, as their
+// delegations are submitted again anyway.
+func (ce *CovenantEmulator) forgetSettledSubmissions(dels []*types.Delegation) {
+	if ce.config.StuckSubmissionTimeout == 0 {
+		return
+	}
+
+	t := &ce.submissions
+	t.mu.Lock()
+	if len(t.entries) == 0 {
+		t.mu.Unlock()
+		return
+	}
+	paged := make(map[string]*types.Delegation, len(dels))
+	for _, del := range dels {
+		if hash, ok := stakingTxHashOf(del); ok {
+			paged[hash] = del
+		}
+	}
+	now := time.Now()
+	maxAge := ce.config.StuckSubmissionMaxAge
+	var unpaged []string
+	for hash, sub := range t.entries {
+		if del, ok := paged[hash]; ok {
+			if ce.isSettled(del) {
+				delete(t.entries, hash)
+			}
+			continue
+		}
+		if maxAge != 0 && now.Sub(sub.submittedAt) >= maxAge {
+			delete(t.entries, hash)
+			continue
+		}
+		unpaged = append(unpaged, hash)
+	}
+	t.mu.Unlock()
+
+	for _, hash := range unpaged {
+		stakingTxHash, err := chainhash.NewHashFromStr(hash)
+		if err != nil {
+			t.forget(hash)
+			continue
+		}
+		del, err := ce.cc.QueryBTCDelegation(*stakingTxHash)
+		switch {
+		case errors.Is(err, clientcontroller.ErrDelegationNotFound):
+			t.forget(hash)
+		case err != nil:
+			ce.logger.Debug("failed to query the delegation of a tracked submission",
+				zap.String("staking_tx_hash", hash),
+				zap.Error(err),
+			)
+		case ce.isSettled(del):
+			t.forget(hash)
+		}
+	}
+}
+
+// isSettled returns whether the delegation no longer needs our sigs, i.e., it
+// is signed by us or reaches the covenant quorum
+func (ce *CovenantEmulator) isSettled(del *types.Delegation) bool {
+	return ce.isSignedByUs(del) || del.HasCovenantQuorum(ce.params.Load().CovenantQuorum)
+}
+
+// removeStuckSubmissions defers the given delegations, which are pending
+// without our sigs, of which the sigs have been submitted within the
+// configured timeout, so that no conflicting tx is submitted while the
+// previous one may still be included. A submission which is not included
+// after the timeout is reported as stuck, and the delegation is submitted
+// again once the submission reaches the max age. The delegation is not
+// submitted again before, as the mempool rejects a tx of the submitter
+// account with the same sequence as the stuck one.
+func (ce *CovenantEmulator) removeStuckSubmissions(dels []*types.Delegation) []*types.Delegation {
+	timeout := ce.config.StuckSubmissionTimeout
+	maxAge := ce.config.StuckSubmissionMaxAge
+	if timeout == 0 {
+		return dels
+	}
+
+	t := &ce.submissions
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.entries) == 0 {
+		return dels
+	}
+
+	now := time.Now()
+	remaining := make([]*types.Delegation, 0, len(dels))
+	for _, del := range dels {
+		hash, ok := stakingTxHashOf(del)
+		if !ok {
+			remaining = append(remaining, del)
+			continue
+		}
+
+		sub, ok := t.entries[hash]
+		if !ok {
+			remaining = append(remaining, del)
+			continue
+		}
+		age := now.Sub(sub.submittedAt)
+		if age < timeout {
+			continue
+		}
+
+		if !sub.reported {
+			sub.reported = true
+			ce.metrics.StuckSubmissions.Inc()
+			ce.logger.Warn("the submitted covenant signatures are not included in the delegation within the timeout",
+				zap.String("staking_tx_hash", hash),
+				zap.String("tx_hash", sub.txHash),
+				zap.Duration("since", age),
+			)
+		}
+		if maxAge != 0 && age >= maxAge {
+			ce.logger.Warn("retrying the delegation of the stuck submission after the max age",
+				zap.String("staking_tx_hash", hash),
+				zap.String("tx_hash", sub.txHash),
+				zap.Duration("max_age", maxAge),
+			)
+			delete(t.entries, hash)
+			remaining = append(remaining, del)
+		}
+	}
+
+	return remaining
+}
@@ -0,0 +1,143 @@
+package covenant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// DecisionTrace is the record of every check and signing step taken while
+// processing a delegation, in order
+type DecisionTrace struct {
+	StakingTxHash string      `json:"staking_tx_hash,omitempty"`
+	StartTime     time.Time   `json:"start_time"`
+	Steps         []TraceStep `json:"steps"`
+	// Outcome is whether the delegation is signed, skipped, or failed to
+	// be signed, regardless of the submission of the sigs
+	Outcome DelegationOutcome `json:"outcome"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// TraceStep is a single check or signing step of a decision trace
+type TraceStep struct {
+	Step   string `json:"step"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// record appends a step which passes if err is nil. It is a no-op on a nil
+// trace so that signing does not need to check whether tracing is enabled.
+func (t *DecisionTrace) record(step string, err error, detail string) {
+	if t == nil {
+		return
+	}
+
+	s := TraceStep{Step: step, Passed: err == nil, Detail: detail}
+	if err != nil {
+		s.Error = err.Error()
+	}
+	t.Steps = append(t.Steps, s)
+}
+
+func (t *DecisionTrace) setStakingTxHash(hash string) {
+	if t == nil {
+		return
+	}
+
+	t.StakingTxHash = hash
+}
+
+// finish sets the outcome of the trace by the error of signing
+func (t *DecisionTrace) finish(err error) {
+	if t == nil {
+		return
+	}
+
+	switch {
+	case err == nil:
+		t.Outcome = OutcomeSigned
+	case errors.Is(err, ErrQuorumAlreadyReached),
+		errors.Is(err, ErrUnsignableDelegation),
+		errors.Is(err, ErrEncryptionKey),
+		errors.Is(err, ErrSlashingTxFeeTooHigh),
+		errors.Is(err, ErrSupersededCommittee):
+		t.Outcome = OutcomeSkipped
+	default:
+		t.Outcome = OutcomeFailed
+	}
+	if err != nil {
+		t.Error = err.Error()
+	}
+}
+
+// TraceDelegation validates and signs the given delegation without submitting
+// the sigs, and returns the trace of every decision taken
+func (ce *CovenantEmulator) TraceDelegation(btcDel *types.Delegation) *DecisionTrace {
+	trace := &DecisionTrace{StartTime: time.Now()}
+	_, err := ce.signDelegation(btcDel, ce.params.Load(), ce.logger, trace)
+	trace.finish(err)
+
+	return trace
+}
+
+// traceWriter appends decision traces to a file as newline-delimited JSON
+type traceWriter struct {
+	mu   sync.Mutex
+	path string
+}
+
+// newDecisionTrace returns a new trace if the decision trace file is
+// configured, and nil otherwise
+func (ce *CovenantEmulator) newDecisionTrace() *DecisionTrace {
+	if ce.traces == nil {
+		return nil
+	}
+
+	return &DecisionTrace{StartTime: time.Now()}
+}
+
+// writeDecisionTrace finishes the given trace and appends it to the decision
+// trace file. Failures are logged as the trace is only for auditing.
+func (ce *CovenantEmulator) writeDecisionTrace(trace *DecisionTrace, err error) {
+	if trace == nil {
+		return
+	}
+	trace.finish(err)
+
+	if err := ce.traces.write(trace); err != nil {
+		ce.logger.Warn("failed to write the decision trace",
+			zap.String("staking_tx_hash", trace.StakingTxHash),
+			zap.Error(err),
+		)
+	}
+}
+
+func (w *traceWriter) write(trace *DecisionTrace) error {
+	line, err := json.Marshal(trace)
+	if err != nil {
+		return fmt.Errorf("failed to encode the decision trace: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open the decision trace file %s: %w", w.path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write the decision trace file %s: %w", w.path, err)
+	}
+
+	return nil
+}
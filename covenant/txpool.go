@@ -0,0 +1,54 @@
+package covenant
+
+import (
+	"bytes"
+	"encoding/hex"
+	"sync"
+
+	"github.com/btcsuite/btcd/wire"
+
+	bbntypes "github.com/babylonchain/babylon/types"
+)
+
+// txBuffers holds the buffers of decoding a hex encoded tx
+type txBuffers struct {
+	hexBuf []byte
+	txBuf  []byte
+	reader bytes.Reader
+}
+
+var txBuffersPool = sync.Pool{
+	New: func() any { return &txBuffers{} },
+}
+
+// decodeTx decodes the given hex encoded tx. If tx buffer pooling is enabled,
+// the buffers of decoding are reused across delegations instead of allocated
+// for each tx. The returned tx does not reference the buffers so it remains
+// valid after they are returned to the pool.
+func (ce *CovenantEmulator) decodeTx(txHex string) (*wire.MsgTx, error) {
+	if !ce.config.PoolTxBuffers {
+		tx, _, err := bbntypes.NewBTCTxFromHex(txHex)
+		return tx, err
+	}
+
+	bufs := txBuffersPool.Get().(*txBuffers)
+	defer txBuffersPool.Put(bufs)
+
+	bufs.hexBuf = append(bufs.hexBuf[:0], txHex...)
+	n := hex.DecodedLen(len(bufs.hexBuf))
+	if cap(bufs.txBuf) < n {
+		bufs.txBuf = make([]byte, n)
+	}
+	bufs.txBuf = bufs.txBuf[:n]
+	if _, err := hex.Decode(bufs.txBuf, bufs.hexBuf); err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	bufs.reader.Reset(bufs.txBuf)
+	if err := tx.Deserialize(&bufs.reader); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
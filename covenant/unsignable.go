@@ -0,0 +1,69 @@
+package covenant
+
+import (
+	"sort"
+	"sync"
+)
+
+// unsignableSet tracks the delegations which cannot be signed by this version
+// of the emulator or have a finality provider pk unusable for encryption by
+// their staking tx hashes, so that they are skipped rather than retried every round
+type unsignableSet struct {
+	mu     sync.Mutex
+	hashes map[string]struct{}
+}
+
+func (u *unsignableSet) add(stakingTxHash string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.hashes == nil {
+		u.hashes = make(map[string]struct{})
+	}
+	u.hashes[stakingTxHash] = struct{}{}
+}
+
+func (u *unsignableSet) contains(stakingTxHash string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	_, ok := u.hashes[stakingTxHash]
+	return ok
+}
+
+func (u *unsignableSet) list() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	hashes := make([]string, 0, len(u.hashes))
+	for hash := range u.hashes {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	return hashes
+}
+
+func (u *unsignableSet) replace(hashes []string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.hashes = make(map[string]struct{}, len(hashes))
+	for _, hash := range hashes {
+		u.hashes[hash] = struct{}{}
+	}
+}
+
+// retainPending removes the delegations which are not in the given pending
+// set, which must cover every pending page, e.g., as they are activated,
+// withdrawn, or unbonded
+func (u *unsignableSet) retainPending(pending map[string]struct{}) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for hash := range u.hashes {
+		if _, ok := pending[hash]; !ok {
+			delete(u.hashes, hash)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package covenant
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/covenant-emulator/clientcontroller"
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// removeVanished re-queries the delegations of the given sigs and removes the
+// sigs of those which no longer exist on the consumer chain, along with their
+// loggers. It returns the remaining sigs and loggers and the number removed.
+// Delegations that fail to be queried for other reasons are kept.
+func (ce *CovenantEmulator) removeVanished(
+	covenantSigs []*types.CovenantSigs,
+	delLoggers []*zap.Logger,
+) ([]*types.CovenantSigs, []*zap.Logger, int) {
+	remainingSigs := make([]*types.CovenantSigs, 0, len(covenantSigs))
+	remainingLoggers := make([]*zap.Logger, 0, len(delLoggers))
+	for i, covSigs := range covenantSigs {
+		_, err := ce.cc.QueryBTCDelegation(covSigs.StakingTxHash)
+		if errors.Is(err, clientcontroller.ErrDelegationNotFound) {
+			ce.recordVanished(covSigs.StakingTxHash.String(), delLoggers[i])
+			continue
+		}
+		remainingSigs = append(remainingSigs, covSigs)
+		remainingLoggers = append(remainingLoggers, delLoggers[i])
+	}
+
+	return remainingSigs, remainingLoggers, len(covenantSigs) - len(remainingSigs)
+}
+
+// recordVanished records that the delegation with the given staking tx hash no
+// longer exists on the consumer chain. This is expected when the delegation is
+// withdrawn while being processed, so it is not logged as an error.
+func (ce *CovenantEmulator) recordVanished(stakingTxHash string, logger *zap.Logger) {
+	ce.metrics.DelegationsVanished.Inc()
+	ce.stats.update(func(s *MetricsSnapshot) { s.Vanished++ })
+	ce.backoff.recordSuccess(stakingTxHash)
+	ce.reportResult(stakingTxHash, OutcomeSkipped, clientcontroller.ErrDelegationNotFound.Error(), "")
+	logger.Debug("skipping the delegation which no longer exists on the consumer chain",
+		zap.String("staking_tx_hash", stakingTxHash))
+}
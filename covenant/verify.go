@@ -0,0 +1,170 @@
+package covenant
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/babylonchain/babylon/btcstaking"
+	asig "github.com/babylonchain/babylon/crypto/schnorr-adaptor-signature"
+	bbntypes "github.com/babylonchain/babylon/types"
+	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// SigType is the type of a covenant signature on a delegation
+type SigType string
+
+const (
+	// SigTypeStakingSlashing is the adaptor sig on the slashing tx spending
+	// the staking output, one of which is produced per finality provider
+	SigTypeStakingSlashing SigType = "staking-slashing"
+	// SigTypeUnbonding is the Schnorr sig on the unbonding tx
+	SigTypeUnbonding SigType = "unbonding"
+	// SigTypeUnbondingSlashing is the adaptor sig on the slashing tx spending
+	// the unbonding output, one of which is produced per finality provider
+	SigTypeUnbondingSlashing SigType = "unbonding-slashing"
+)
+
+// VerifyCovenantSig checks whether the given sig of the given covenant pk is
+// valid for the delegation under the current staking params, regardless of
+// where it is produced, e.g., by another committee member or by an older
+// version of the emulator. An adaptor sig is valid if it is encrypted for any
+// of the finality providers of the delegation. It returns an error wrapping
+// ErrInvalidCovenantSig if the sig is invalid.
+func (ce *CovenantEmulator) VerifyCovenantSig(
+	btcDel *types.Delegation,
+	covenantPk *btcec.PublicKey,
+	sig []byte,
+	sigType SigType,
+) error {
+	if err := ce.UpdateParams(); err != nil {
+		return fmt.Errorf("failed to get staking params: %w", err)
+	}
+	params := ce.params.Load()
+
+	if covenantPk == nil {
+		return fmt.Errorf("empty covenant pk")
+	}
+	if !isCovenantMember(covenantPk, params.CovenantPks) {
+		return fmt.Errorf("%w: the pk %x is not in the covenant committee",
+			ErrInvalidCovenantSig, schnorr.SerializePubKey(covenantPk))
+	}
+
+	stakingInfo, unbondingInfo, err := BuildDelegationScripts(btcDel, params, ce.btcNetOf(params))
+	if err != nil {
+		return err
+	}
+
+	switch sigType {
+	case SigTypeStakingSlashing:
+		slashingPathInfo, err := stakingInfo.SlashingPathSpendInfo()
+		if err != nil {
+			return err
+		}
+		return ce.verifyAdaptorSig(
+			btcDel,
+			btcDel.SlashingTxHex,
+			stakingInfo.StakingOutput.PkScript,
+			stakingInfo.StakingOutput.Value,
+			slashingPathInfo.GetPkScriptPath(),
+			covenantPk,
+			sig,
+		)
+
+	case SigTypeUnbonding:
+		unbondingPathInfo, err := stakingInfo.UnbondingPathSpendInfo()
+		if err != nil {
+			return err
+		}
+		unbondingMsgTx, _, err := bbntypes.NewBTCTxFromHex(btcDel.BtcUndelegation.UnbondingTxHex)
+		if err != nil {
+			return err
+		}
+		if err := btcstaking.VerifyTransactionSigWithOutputData(
+			unbondingMsgTx,
+			stakingInfo.StakingOutput.PkScript,
+			stakingInfo.StakingOutput.Value,
+			unbondingPathInfo.GetPkScriptPath(),
+			covenantPk,
+			sig,
+		); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCovenantSig, err)
+		}
+		return nil
+
+	case SigTypeUnbondingSlashing:
+		slashingPathInfo, err := unbondingInfo.SlashingPathSpendInfo()
+		if err != nil {
+			return err
+		}
+		return ce.verifyAdaptorSig(
+			btcDel,
+			btcDel.BtcUndelegation.SlashingTxHex,
+			unbondingInfo.UnbondingOutput.PkScript,
+			unbondingInfo.UnbondingOutput.Value,
+			slashingPathInfo.GetPkScriptPath(),
+			covenantPk,
+			sig,
+		)
+
+	default:
+		return fmt.Errorf("unsupported sig type %s, expected one of %s, %s, %s",
+			sigType, SigTypeStakingSlashing, SigTypeUnbonding, SigTypeUnbondingSlashing)
+	}
+}
+
+// verifyAdaptorSig verifies the adaptor sig on the given slashing tx against
+// the encryption key of each finality provider of the delegation
+func (ce *CovenantEmulator) verifyAdaptorSig(
+	btcDel *types.Delegation,
+	slashingTxHex string,
+	fundingPkScript []byte,
+	fundingValue int64,
+	slashingPathScript []byte,
+	covenantPk *btcec.PublicKey,
+	sig []byte,
+) error {
+	adaptorSig, err := asig.NewAdaptorSignatureFromBytes(sig)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCovenantSig, err)
+	}
+	slashingTx, err := bstypes.NewBTCSlashingTxFromHex(slashingTxHex)
+	if err != nil {
+		return err
+	}
+	encKeys, err := ce.deriveEncKeys(btcDel.FpBtcPks)
+	if err != nil {
+		return err
+	}
+
+	for _, encKey := range encKeys {
+		if err := slashingTx.EncVerifyAdaptorSignature(
+			fundingPkScript,
+			fundingValue,
+			slashingPathScript,
+			covenantPk,
+			encKey,
+			adaptorSig,
+		); err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: the adaptor sig is not valid for any of the %d finality providers",
+		ErrInvalidCovenantSig, len(encKeys))
+}
+
+// isCovenantMember returns whether the given pk is one of the covenant pks
+func isCovenantMember(pk *btcec.PublicKey, covenantPks []*btcec.PublicKey) bool {
+	pkBytes := schnorr.SerializePubKey(pk)
+	for _, covPk := range covenantPks {
+		if bytes.Equal(schnorr.SerializePubKey(covPk), pkBytes) {
+			return true
+		}
+	}
+
+	return false
+}
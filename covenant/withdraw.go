@@ -0,0 +1,24 @@
+package covenant
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// WithdrawCovenantSig retracts the covenant sigs submitted for the delegation
+// with the given staking tx hash, e.g., sigs submitted in error during an
+// incident.
+//
+// Babylon does not support retracting covenant sigs: once included, they count
+// towards the covenant quorum and a delegation reaching the quorum is activated
+// irrevocably. So ErrWithdrawalUnsupported is always returned for a valid hash,
+// and incident response must instead stop the emulator before more sigs are
+// submitted.
+func (ce *CovenantEmulator) WithdrawCovenantSig(stakingTxHash string) error {
+	if _, err := chainhash.NewHashFromStr(stakingTxHash); err != nil {
+		return fmt.Errorf("invalid staking tx hash %s: %w", stakingTxHash, err)
+	}
+
+	return fmt.Errorf("%w: staking tx hash %s", ErrWithdrawalUnsupported, stakingTxHash)
+}
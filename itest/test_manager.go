@@ -1,6 +1,7 @@
 package e2etest
 
 import (
+	"context"
 	"math/rand"
 	"os"
 	"sync"
@@ -196,6 +197,7 @@ func (tm *TestManager) WaitForNPendingDels(t *testing.T, n int) []*types.Delegat
 	)
 	require.Eventually(t, func() bool {
 		dels, err = tm.CovBBNClient.QueryPendingDelegations(
+			context.Background(),
 			tm.CovenanConfig.DelegationLimit,
 		)
 		if err != nil {
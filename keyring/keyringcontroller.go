@@ -1,6 +1,7 @@
 package keyring
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	sdksecp256k1 "github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	"github.com/cosmos/go-bip39"
 
 	"github.com/babylonchain/covenant-emulator/types"
@@ -126,3 +128,85 @@ func (kc *ChainKeyringController) GetChainPrivKey(passphrase string) (*sdksecp25
 		return nil, fmt.Errorf("unsupported key type in keyring")
 	}
 }
+
+// ExportChainKey exports the key as an ASCII-armored private key that is
+// encrypted with the given encryption passphrase
+func (kc *ChainKeyringController) ExportChainKey(passphrase, encPassphrase string) (string, error) {
+	kc.input.Reset(passphrase)
+	armor, err := kc.kr.ExportPrivKeyArmor(kc.fpName, encPassphrase)
+	if err != nil {
+		return "", fmt.Errorf("failed to export private key: %w", err)
+	}
+
+	return armor, nil
+}
+
+// ImportChainKey imports the ASCII-armored private key that is encrypted with
+// the given encryption passphrase and returns its public key
+func (kc *ChainKeyringController) ImportChainKey(armor, encPassphrase, passphrase string) (*btcec.PublicKey, error) {
+	// we need to repeat the passphrase to mock the reentry
+	kc.input.Reset(passphrase + "\n" + passphrase)
+	if err := kc.kr.ImportPrivKey(kc.fpName, armor, encPassphrase); err != nil {
+		return nil, fmt.Errorf("failed to import private key: %w", err)
+	}
+
+	sk, err := kc.GetChainPrivKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return btcec.ParsePubKey(sk.PubKey().Bytes())
+}
+
+// DeleteChainKey deletes the key from the keyring
+func (kc *ChainKeyringController) DeleteChainKey(passphrase string) error {
+	kc.input.Reset(passphrase)
+	return kc.kr.Delete(kc.fpName)
+}
+
+// HasChainKey returns whether the key exists in the keyring
+func (kc *ChainKeyringController) HasChainKey(passphrase string) (bool, error) {
+	kc.input.Reset(passphrase)
+	_, err := kc.kr.Key(kc.fpName)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, sdkerrors.ErrKeyNotFound) {
+		return false, nil
+	}
+
+	return false, fmt.Errorf("failed to get key: %w", err)
+}
+
+// ListChainKeys returns the name and pk of every secp256k1 key in the keyring.
+// The returned key infos carry neither the mnemonic nor the private key.
+func (kc *ChainKeyringController) ListChainKeys(passphrase string) ([]*types.ChainKeyInfo, error) {
+	kc.input.Reset(passphrase)
+	records, err := kc.kr.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	keys := make([]*types.ChainKeyInfo, 0, len(records))
+	for _, record := range records {
+		pubKey, err := record.GetPubKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the pk of key %s: %w", record.Name, err)
+		}
+		secpPubKey, ok := pubKey.(*sdksecp256k1.PubKey)
+		if !ok {
+			// not a key the covenant can sign with
+			continue
+		}
+		pk, err := btcec.ParsePubKey(secpPubKey.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pk of key %s: %w", record.Name, err)
+		}
+		keys = append(keys, &types.ChainKeyInfo{
+			Name:      record.Name,
+			PublicKey: pk,
+		})
+	}
+
+	return keys, nil
+}
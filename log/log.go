@@ -58,7 +58,7 @@ func NewRootLogger(format string, level string, w io.Writer) (*zap.Logger, error
 	)), nil
 }
 
-func NewRootLoggerWithFile(logFile string, level string) (*zap.Logger, error) {
+func NewRootLoggerWithFile(logFile string, format string, level string) (*zap.Logger, error) {
 	if err := util.MakeDirectory(filepath.Dir(logFile)); err != nil {
 		return nil, err
 	}
@@ -68,9 +68,18 @@ func NewRootLoggerWithFile(logFile string, level string) (*zap.Logger, error) {
 	}
 	mw := io.MultiWriter(os.Stdout, f)
 
-	logger, err := NewRootLogger("console", level, mw)
+	logger, err := NewRootLogger(format, level, mw)
 	if err != nil {
 		return nil, err
 	}
 	return logger, nil
 }
+
+// WithSampling wraps the logger so that, within each second, only the first
+// 100 entries with the same level and message and every 100th entry after
+// that are logged
+func WithSampling(logger *zap.Logger) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewSamplerWithOptions(core, time.Second, 100, 100)
+	}))
+}
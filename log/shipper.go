@@ -0,0 +1,168 @@
+package log
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	shipperDialTimeout     = 5 * time.Second
+	shipperWriteTimeout    = 5 * time.Second
+	shipperMinReconnectGap = time.Second
+	shipperMaxReconnectGap = time.Minute
+)
+
+// Shipper ships log entries as newline-delimited JSON to a remote collector,
+// e.g., the TCP or UDP input of a syslog server or a Loki agent. Entries are
+// buffered in memory so that logging never blocks on the collector, and are
+// dropped if the buffer is full, e.g., during a collector outage.
+type Shipper struct {
+	network string
+	address string
+
+	entries chan []byte
+	dropped atomic.Uint64
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// NewShipper creates a shipper to the collector at the given network and
+// address which buffers up to bufferSize entries, and starts shipping
+func NewShipper(network, address string, bufferSize int) *Shipper {
+	s := &Shipper{
+		network: network,
+		address: address,
+		entries: make(chan []byte, bufferSize),
+		quit:    make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.ship()
+
+	return s
+}
+
+// Write buffers a single encoded log entry without blocking
+func (s *Shipper) Write(p []byte) (int, error) {
+	entry := make([]byte, len(p))
+	copy(entry, p)
+
+	select {
+	case s.entries <- entry:
+	default:
+		s.dropped.Add(1)
+	}
+
+	return len(p), nil
+}
+
+// Sync is a no-op as the entries are shipped asynchronously
+func (s *Shipper) Sync() error {
+	return nil
+}
+
+// Dropped returns the number of entries dropped as the buffer was full
+func (s *Shipper) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close stops shipping after trying to ship the buffered entries once
+func (s *Shipper) Close() error {
+	s.once.Do(func() {
+		close(s.quit)
+		s.wg.Wait()
+	})
+
+	return nil
+}
+
+// ship sends the buffered entries in order. While the collector is
+// unreachable, the entry at hand is retried with an exponential backoff and
+// the following ones accumulate in the buffer.
+func (s *Shipper) ship() {
+	defer s.wg.Done()
+
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	send := func(entry []byte) error {
+		if conn == nil {
+			c, err := net.DialTimeout(s.network, s.address, shipperDialTimeout)
+			if err != nil {
+				return err
+			}
+			conn = c
+		}
+
+		if err := conn.SetWriteDeadline(time.Now().Add(shipperWriteTimeout)); err != nil {
+			conn.Close()
+			conn = nil
+			return err
+		}
+		if _, err := conn.Write(entry); err != nil {
+			conn.Close()
+			conn = nil
+			return err
+		}
+
+		return nil
+	}
+
+	for {
+		var entry []byte
+		select {
+		case entry = <-s.entries:
+		case <-s.quit:
+			// ship the remaining entries unless the collector is unreachable
+			for {
+				select {
+				case entry := <-s.entries:
+					if err := send(entry); err != nil {
+						s.dropped.Add(uint64(len(s.entries)) + 1)
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+
+		reconnectGap := shipperMinReconnectGap
+		for send(entry) != nil {
+			select {
+			case <-time.After(reconnectGap):
+				reconnectGap = min(2*reconnectGap, shipperMaxReconnectGap)
+			case <-s.quit:
+				s.dropped.Add(uint64(len(s.entries)) + 1)
+				return
+			}
+		}
+	}
+}
+
+// WithShipper tees the entries of the logger which are enabled at its level
+// to the given shipper, encoded as JSON
+func WithShipper(logger *zap.Logger, shipper *Shipper) *zap.Logger {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	cfg.LevelKey = "lvl"
+
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, zapcore.NewCore(
+			zapcore.NewJSONEncoder(cfg),
+			shipper,
+			core,
+		))
+	}))
+}
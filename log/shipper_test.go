@@ -0,0 +1,130 @@
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// collect accepts a single connection of the listener and sends each line
+// received on it to the returned channel, which is closed once the connection
+// is closed
+func collect(t *testing.T, listener net.Listener) <-chan string {
+	lines := make(chan string, 100)
+	go func() {
+		defer close(lines)
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+
+	return lines
+}
+
+func TestShipperDrainsOnClose(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	lines := collect(t, listener)
+
+	shipper := NewShipper("tcp", listener.Addr().String(), 10)
+	for i := 0; i < 5; i++ {
+		_, err := shipper.Write([]byte(fmt.Sprintf("entry %d\n", i)))
+		require.NoError(t, err)
+	}
+	require.NoError(t, shipper.Close())
+	// closing again is a no-op
+	require.NoError(t, shipper.Close())
+
+	received := make([]string, 0, 5)
+	for line := range lines {
+		received = append(received, line)
+	}
+	require.Equal(t, []string{"entry 0", "entry 1", "entry 2", "entry 3", "entry 4"}, received)
+	require.Zero(t, shipper.Dropped())
+}
+
+func TestShipperDropsWhenUnreachable(t *testing.T) {
+	// the address of a closed listener, on which nothing is listening
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	// writing never blocks, while the entries exceeding the buffer are dropped
+	shipper := NewShipper("tcp", address, 2)
+	for i := 0; i < 10; i++ {
+		n, err := shipper.Write([]byte("entry\n"))
+		require.NoError(t, err)
+		require.Equal(t, len("entry\n"), n)
+	}
+	require.GreaterOrEqual(t, shipper.Dropped(), uint64(10-2-1))
+
+	// the entries left upon closing are dropped as well
+	require.NoError(t, shipper.Close())
+	require.Equal(t, uint64(10), shipper.Dropped())
+}
+
+func TestShipperRetriesUntilReachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	shipper := NewShipper("tcp", address, 10)
+	t.Cleanup(func() { shipper.Close() })
+	_, err = shipper.Write([]byte("entry\n"))
+	require.NoError(t, err)
+
+	// the collector comes up after the first attempt failed
+	time.Sleep(100 * time.Millisecond)
+	listener, err = net.Listen("tcp", address)
+	require.NoError(t, err)
+	lines := collect(t, listener)
+
+	select {
+	case line := <-lines:
+		require.Equal(t, "entry", line)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the entry is not shipped once the collector is reachable")
+	}
+	require.Zero(t, shipper.Dropped())
+}
+
+func TestWithShipper(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	lines := collect(t, listener)
+
+	shipper := NewShipper("tcp", listener.Addr().String(), 10)
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(io.Discard), zap.InfoLevel)
+	logger := WithShipper(zap.New(core), shipper)
+	logger.Debug("entry below the level")
+	logger.Info("shipped entry", zap.String("key", "value"))
+	require.NoError(t, shipper.Close())
+
+	line, ok := <-lines
+	require.True(t, ok)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(line), &entry))
+	require.Equal(t, "info", entry["lvl"])
+	require.Equal(t, "shipped entry", entry["msg"])
+	require.Equal(t, "value", entry["key"])
+	_, ok = <-lines
+	require.False(t, ok)
+}
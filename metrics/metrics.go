@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	once           sync.Once
+	covenantMetric *CovenantMetrics
+)
+
+// CovenantMetrics contains the Prometheus collectors of the covenant emulator
+type CovenantMetrics struct {
+	// KeyringUnlockFailures counts the failures of retrieving the covenant
+	// private key from the keyring
+	KeyringUnlockFailures prometheus.Counter
+	// DelegationLimitReached counts the rounds in which the number of queried
+	// pending delegations equals the configured limit
+	DelegationLimitReached prometheus.Counter
+	// QuorumAlreadyReached counts the delegations skipped because they
+	// already have a covenant quorum
+	QuorumAlreadyReached prometheus.Counter
+	// UnsignableDelegations counts the delegations skipped because they
+	// cannot be signed by this version of the emulator
+	UnsignableDelegations prometheus.Counter
+	// SupersededCommitteeDelegations counts the delegations skipped because
+	// they are staked under a covenant committee other than the current one
+	SupersededCommitteeDelegations prometheus.Counter
+	// StuckSubmissions counts the submissions of which the sigs are not
+	// included in the delegation within the configured timeout
+	StuckSubmissions prometheus.Counter
+	// ReorgedStakingTxs counts the pending delegations of which the staking
+	// tx is found reorged out of the BTC chain
+	ReorgedStakingTxs prometheus.Counter
+	// InvalidatedSigs counts the delegations of which our submitted covenant
+	// sigs are found invalid under the current staking params
+	InvalidatedSigs prometheus.Counter
+	// DelegationsVanished counts the delegations skipped because they no
+	// longer exist on the consumer chain when their sigs are submitted
+	DelegationsVanished prometheus.Counter
+	// SubmissionConfirmations counts the waits for submitted covenant sigs to
+	// be included in the delegations by the outcome of confirmed or unconfirmed
+	SubmissionConfirmations *prometheus.CounterVec
+	// ClientBreakerState is the state of the circuit breaker around
+	// the consumer chain client
+	ClientBreakerState prometheus.Gauge
+	// DelegationsNearExpiry is the number of pending delegations of which the
+	// staking timelock expires within the configured window
+	DelegationsNearExpiry prometheus.Gauge
+	// QuorumProgress is the number of pending delegations seen by the last
+	// round by their progress towards the covenant quorum, e.g., "1/3"
+	QuorumProgress *prometheus.GaugeVec
+	// Standby is 1 if the emulator is standby and 0 if it is active
+	Standby prometheus.Gauge
+	// ParamsInvalid is 1 if the staking params on the consumer chain are
+	// invalid, e.g., have a malformed slashing address, and 0 otherwise
+	ParamsInvalid prometheus.Gauge
+	// TickDelegations counts the pending delegations seen by the rounds
+	// of the submission loop
+	TickDelegations prometheus.Counter
+	// TickOutcomes counts the outcomes of the delegations seen by the rounds
+	// of the submission loop by the outcome of signed, skipped, or failed
+	TickOutcomes *prometheus.CounterVec
+}
+
+// NewCovenantMetrics returns the collectors of the covenant emulator. The
+// collectors are created and registered to the default Prometheus registry
+// only once so that multiple emulators in the same process share them.
+func NewCovenantMetrics() *CovenantMetrics {
+	once.Do(func() {
+		covenantMetric = &CovenantMetrics{
+			KeyringUnlockFailures: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: "covenant_keyring_unlock_failures_total",
+				Help: "The total number of failures to unlock the covenant key from the keyring",
+			}),
+			DelegationLimitReached: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: "covenant_delegation_limit_reached_total",
+				Help: "The total number of rounds in which the queried pending delegations reach the delegation limit",
+			}),
+			QuorumAlreadyReached: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: "covenant_quorum_already_reached_total",
+				Help: "The total number of delegations skipped because they already have a covenant quorum",
+			}),
+			UnsignableDelegations: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: "covenant_unsignable_delegations_total",
+				Help: "The total number of delegations skipped because they cannot be signed by this version of the covenant emulator",
+			}),
+			SupersededCommitteeDelegations: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: "covenant_superseded_committee_delegations_total",
+				Help: "The total number of delegations skipped because they are staked under a superseded covenant committee",
+			}),
+			StuckSubmissions: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: "covenant_stuck_submissions_total",
+				Help: "The total number of submitted covenant signatures which are not included in the delegation within the timeout",
+			}),
+			ReorgedStakingTxs: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: "covenant_reorged_staking_txs_total",
+				Help: "The total number of pending delegations of which the staking tx is reorged out of the BTC chain",
+			}),
+			InvalidatedSigs: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: "covenant_invalidated_sigs_total",
+				Help: "The total number of delegations of which the submitted covenant signatures are invalid under the current staking params",
+			}),
+			DelegationsVanished: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: "covenant_delegations_vanished_total",
+				Help: "The total number of delegations skipped because they no longer exist on the consumer chain",
+			}),
+			SubmissionConfirmations: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "covenant_submission_confirmations_total",
+				Help: "The total number of waits for submitted covenant signatures to be included in the delegations by outcome",
+			}, []string{"outcome"}),
+			ClientBreakerState: prometheus.NewGauge(prometheus.GaugeOpts{
+				Name: "covenant_client_breaker_state",
+				Help: "The state of the circuit breaker around the consumer chain client (0: closed, 1: open, 2: half-open)",
+			}),
+			DelegationsNearExpiry: prometheus.NewGauge(prometheus.GaugeOpts{
+				Name: "covenant_delegations_near_expiry",
+				Help: "The number of pending delegations of which the staking timelock expires within the warning window",
+			}),
+			QuorumProgress: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "covenant_quorum_progress",
+				Help: "The number of pending delegations by the number of covenant signatures they have out of the quorum",
+			}, []string{"progress"}),
+			Standby: prometheus.NewGauge(prometheus.GaugeOpts{
+				Name: "covenant_standby",
+				Help: "Whether the covenant emulator is standby (1) or active (0)",
+			}),
+			ParamsInvalid: prometheus.NewGauge(prometheus.GaugeOpts{
+				Name: "covenant_params_invalid",
+				Help: "Whether the staking params on the consumer chain are invalid (1) and signing is halted, or valid (0)",
+			}),
+			TickDelegations: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: "covenant_tick_delegations_total",
+				Help: "The total number of pending delegations seen by the rounds of the submission loop",
+			}),
+			TickOutcomes: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "covenant_tick_outcomes_total",
+				Help: "The total number of delegations seen by the rounds of the submission loop by outcome",
+			}, []string{"outcome"}),
+		}
+
+		prometheus.MustRegister(
+			covenantMetric.KeyringUnlockFailures,
+			covenantMetric.DelegationLimitReached,
+			covenantMetric.QuorumAlreadyReached,
+			covenantMetric.UnsignableDelegations,
+			covenantMetric.SupersededCommitteeDelegations,
+			covenantMetric.StuckSubmissions,
+			covenantMetric.ReorgedStakingTxs,
+			covenantMetric.InvalidatedSigs,
+			covenantMetric.DelegationsVanished,
+			covenantMetric.SubmissionConfirmations,
+			covenantMetric.ClientBreakerState,
+			covenantMetric.DelegationsNearExpiry,
+			covenantMetric.QuorumProgress,
+			covenantMetric.Standby,
+			covenantMetric.ParamsInvalid,
+			covenantMetric.TickDelegations,
+			covenantMetric.TickOutcomes,
+		)
+	})
+
+	return covenantMetric
+}
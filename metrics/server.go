@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// Start runs the Prometheus server exposing the metrics at the given address
+// in a separate goroutine
+func Start(addr string, logger *zap.Logger) {
+	go start(addr, logger)
+}
+
+func start(addr string, logger *zap.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	logger.Info("starting metrics server", zap.String("address", addr))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("metrics server failed", zap.Error(err))
+	}
+}
@@ -33,7 +33,7 @@ func GenValidSlashingRate(r *rand.Rand) sdkmath.LegacyDec {
 	return sdkmath.LegacyNewDecWithPrec(int64(datagen.RandomInt(r, 41)+10), 2)
 }
 
-func GenRandomParams(r *rand.Rand, t *testing.T) *types.StakingParams {
+func GenRandomParams(r *rand.Rand, t testing.TB) *types.StakingParams {
 	covThreshold := datagen.RandomInt(r, 5) + 1
 	covNum := covThreshold * 2
 	covenantPks := make([]*btcec.PublicKey, 0, covNum)
@@ -57,7 +57,7 @@ func GenRandomParams(r *rand.Rand, t *testing.T) *types.StakingParams {
 	}
 }
 
-func GenBtcPublicKeys(r *rand.Rand, t *testing.T, num int) []*btcec.PublicKey {
+func GenBtcPublicKeys(r *rand.Rand, t testing.TB, num int) []*btcec.PublicKey {
 	pks := make([]*btcec.PublicKey, 0, num)
 	for i := 0; i < num; i++ {
 		_, covPk, err := datagen.GenRandomBTCKeyPair(r)
@@ -0,0 +1,429 @@
+// Package fakeclient provides an in-memory ClientController for testing the
+// covenant emulator without a running consumer chain
+package fakeclient
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bbntypes "github.com/babylonchain/babylon/types"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+
+	"github.com/babylonchain/covenant-emulator/clientcontroller"
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+var _ clientcontroller.ClientController = &FakeClientController{}
+
+// FakeClientController keeps the delegations in memory. Submitted covenant
+// signatures are recorded and added to the corresponding delegations, which
+// stop being pending once they reach a covenant quorum.
+type FakeClientController struct {
+	mu sync.Mutex
+
+	params      *types.StakingParams
+	tipHeight   uint64
+	delegations map[chainhash.Hash]*types.Delegation
+	// pending keeps the staking tx hashes of the pending delegations in
+	// the order they are added
+	pending []chainhash.Hash
+
+	submitErrs []error
+	submitted  [][]*types.CovenantSigs
+	reconnects int
+
+	// queryErrs and queryDelay apply to the queries of delegations, of
+	// which the pending ones are counted in pendingQueries
+	queryErrs      []error
+	queryDelay     time.Duration
+	pendingQueries int
+
+	// stuck makes the submissions succeed without the sigs being included,
+	// and submissions counts the successful submissions
+	stuck       bool
+	submissions int
+
+	// submitDelay is the time a submission takes, during which the calls
+	// overlapping it are counted in overlaps
+	submitDelay time.Duration
+	inSubmit    atomic.Int32
+	overlaps    atomic.Int32
+}
+
+// New creates a FakeClientController that serves the given staking params
+func New(params *types.StakingParams) *FakeClientController {
+	return &FakeClientController{
+		params:      params,
+		delegations: make(map[chainhash.Hash]*types.Delegation),
+	}
+}
+
+// AddPendingDelegations adds the given delegations to the pending queue
+func (fc *FakeClientController) AddPendingDelegations(dels ...*types.Delegation) error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	for _, del := range dels {
+		stakingMsgTx, _, err := bbntypes.NewBTCTxFromHex(del.StakingTxHex)
+		if err != nil {
+			return fmt.Errorf("invalid staking tx of delegation: %w", err)
+		}
+		hash := stakingMsgTx.TxHash()
+		if _, ok := fc.delegations[hash]; ok {
+			return fmt.Errorf("delegation %s already exists", hash.String())
+		}
+		fc.delegations[hash] = del
+		fc.pending = append(fc.pending, hash)
+	}
+
+	return nil
+}
+
+// RemoveDelegation removes the delegation with the given staking tx hash as if
+// it is withdrawn from the consumer chain
+func (fc *FakeClientController) RemoveDelegation(stakingTxHash chainhash.Hash) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	delete(fc.delegations, stakingTxHash)
+	pending := make([]chainhash.Hash, 0, len(fc.pending))
+	for _, hash := range fc.pending {
+		if hash != stakingTxHash {
+			pending = append(pending, hash)
+		}
+	}
+	fc.pending = pending
+}
+
+// SetTipHeight sets the height returned by QueryBtcLightClientTipHeight
+func (fc *FakeClientController) SetTipHeight(height uint64) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.tipHeight = height
+}
+
+// InjectSubmitErrors makes the next submissions fail with the given errors in order
+func (fc *FakeClientController) InjectSubmitErrors(errs ...error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.submitErrs = append(fc.submitErrs, errs...)
+}
+
+// InjectQueryErrors makes the next queries of delegations fail with the given
+// errors in order
+func (fc *FakeClientController) InjectQueryErrors(errs ...error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.queryErrs = append(fc.queryErrs, errs...)
+}
+
+// SetQueryDelay makes every query of pending delegations take the given time
+func (fc *FakeClientController) SetQueryDelay(delay time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.queryDelay = delay
+}
+
+// PendingQueries returns the number of queries of pending delegations
+func (fc *FakeClientController) PendingQueries() int {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return fc.pendingQueries
+}
+
+// nextQueryErr pops the next injected query error, if any. It must be called
+// with the lock held.
+func (fc *FakeClientController) nextQueryErr() error {
+	if len(fc.queryErrs) == 0 {
+		return nil
+	}
+	err := fc.queryErrs[0]
+	fc.queryErrs = fc.queryErrs[1:]
+
+	return err
+}
+
+// delayPendingQuery counts a query of pending delegations and waits for the
+// query delay, unless the query is cancelled before
+func (fc *FakeClientController) delayPendingQuery(ctx context.Context) error {
+	fc.mu.Lock()
+	fc.pendingQueries++
+	delay := fc.queryDelay
+	fc.mu.Unlock()
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetSubmitDelay makes every submission take the given time
+func (fc *FakeClientController) SetSubmitDelay(delay time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.submitDelay = delay
+}
+
+// SetStuckSubmissions makes the following submissions succeed without the
+// sigs being added to the delegations, as if the txs are stuck in the mempool
+func (fc *FakeClientController) SetStuckSubmissions(stuck bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.stuck = stuck
+}
+
+// Submissions returns the number of successful submissions, including the
+// stuck ones
+func (fc *FakeClientController) Submissions() int {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return fc.submissions
+}
+
+// Overlaps returns the number of submissions and reconnects which are called
+// while another submission is in progress, i.e., which would collide on the
+// account sequence or swap the client in use
+func (fc *FakeClientController) Overlaps() int {
+	return int(fc.overlaps.Load())
+}
+
+// SubmittedSigs returns the covenant signatures of each successful submission
+func (fc *FakeClientController) SubmittedSigs() [][]*types.CovenantSigs {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	submitted := make([][]*types.CovenantSigs, len(fc.submitted))
+	copy(submitted, fc.submitted)
+
+	return submitted
+}
+
+// EstimateCovenantSigsFee returns a gas of 100000 plus 10000 per sig, priced
+// at 1ubbn per gas
+func (fc *FakeClientController) EstimateCovenantSigsFee(covSigMsgs []*types.CovenantSigs) (uint64, string, error) {
+	gas := uint64(100000)
+	for _, covSigs := range covSigMsgs {
+		gas += 10000 * uint64(len(covSigs.SlashingSigs)+len(covSigs.SlashingUnbondingSigs)+1)
+	}
+
+	return gas, fmt.Sprintf("%dubbn", gas), nil
+}
+
+// BuildCovenantSigsTx returns the JSON encoded staking tx hashes of the given
+// covenant signatures in place of an unsigned tx, without adding the sigs to
+// the delegations
+func (fc *FakeClientController) BuildCovenantSigsTx(covSigMsgs []*types.CovenantSigs, gasLimit uint64) ([]byte, error) {
+	if gasLimit == 0 {
+		return nil, fmt.Errorf("the gas limit of the tx must be positive")
+	}
+
+	hashes := make([]string, 0, len(covSigMsgs))
+	for _, covSigs := range covSigMsgs {
+		hashes = append(hashes, covSigs.StakingTxHash.String())
+	}
+
+	return json.Marshal(hashes)
+}
+
+// Reconnects returns the number of times Reconnect is called
+func (fc *FakeClientController) Reconnects() int {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return fc.reconnects
+}
+
+func (fc *FakeClientController) SubmitCovenantSigs(_ context.Context, covSigMsgs []*types.CovenantSigs) (*types.TxResponse, error) {
+	if fc.inSubmit.Add(1) > 1 {
+		fc.overlaps.Add(1)
+	}
+	defer fc.inSubmit.Add(-1)
+
+	fc.mu.Lock()
+	delay := fc.submitDelay
+	fc.mu.Unlock()
+	time.Sleep(delay)
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if len(fc.submitErrs) > 0 {
+		err := fc.submitErrs[0]
+		fc.submitErrs = fc.submitErrs[1:]
+		return nil, err
+	}
+
+	for _, covSigs := range covSigMsgs {
+		if _, ok := fc.delegations[covSigs.StakingTxHash]; !ok {
+			return nil, fmt.Errorf("%w: %s", clientcontroller.ErrDelegationNotFound, covSigs.StakingTxHash.String())
+		}
+	}
+
+	fc.submissions++
+	if fc.stuck {
+		return &types.TxResponse{TxHash: fmt.Sprintf("fake-stuck-tx-%d", fc.submissions)}, nil
+	}
+
+	for _, covSigs := range covSigMsgs {
+		del := fc.delegations[covSigs.StakingTxHash]
+		del.CovenantSigs = append(del.CovenantSigs, &types.CovenantAdaptorSigInfo{
+			Pk:   covSigs.PublicKey,
+			Sigs: covSigs.SlashingSigs,
+		})
+		if del.BtcUndelegation != nil {
+			del.BtcUndelegation.CovenantUnbondingSigs = append(del.BtcUndelegation.CovenantUnbondingSigs,
+				&types.CovenantSchnorrSigInfo{
+					Pk:  covSigs.PublicKey,
+					Sig: covSigs.UnbondingSig,
+				})
+			del.BtcUndelegation.CovenantSlashingSigs = append(del.BtcUndelegation.CovenantSlashingSigs,
+				&types.CovenantAdaptorSigInfo{
+					Pk:   covSigs.PublicKey,
+					Sigs: covSigs.SlashingUnbondingSigs,
+				})
+		}
+	}
+	fc.submitted = append(fc.submitted, covSigMsgs)
+	fc.removeActivated()
+
+	return &types.TxResponse{TxHash: fmt.Sprintf("fake-tx-%d", len(fc.submitted))}, nil
+}
+
+// removeActivated removes the delegations that reach a covenant quorum from
+// the pending queue. It must be called with the lock held.
+func (fc *FakeClientController) removeActivated() {
+	pending := make([]chainhash.Hash, 0, len(fc.pending))
+	for _, hash := range fc.pending {
+		if !fc.delegations[hash].HasCovenantQuorum(fc.params.CovenantQuorum) {
+			pending = append(pending, hash)
+		}
+	}
+	fc.pending = pending
+}
+
+func (fc *FakeClientController) QueryPendingDelegations(ctx context.Context, limit uint64) ([]*types.Delegation, error) {
+	if err := fc.delayPendingQuery(ctx); err != nil {
+		return nil, err
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if err := fc.nextQueryErr(); err != nil {
+		return nil, err
+	}
+
+	dels := make([]*types.Delegation, 0, len(fc.pending))
+	for _, hash := range fc.pending {
+		if limit != 0 && uint64(len(dels)) == limit {
+			break
+		}
+		dels = append(dels, fc.delegations[hash])
+	}
+
+	return dels, nil
+}
+
+// QueryPendingDelegationsPage pages through the pending delegations using the
+// big-endian offset into the pending queue as the page key
+func (fc *FakeClientController) QueryPendingDelegationsPage(ctx context.Context, limit uint64, pageKey []byte) ([]*types.Delegation, []byte, error) {
+	if err := fc.delayPendingQuery(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if err := fc.nextQueryErr(); err != nil {
+		return nil, nil, err
+	}
+
+	var offset uint64
+	if len(pageKey) != 0 {
+		if len(pageKey) != 8 {
+			return nil, nil, fmt.Errorf("invalid page key %x", pageKey)
+		}
+		offset = binary.BigEndian.Uint64(pageKey)
+	}
+	if offset > uint64(len(fc.pending)) {
+		return nil, nil, fmt.Errorf("page key %x is out of range", pageKey)
+	}
+
+	end := uint64(len(fc.pending))
+	if limit != 0 && offset+limit < end {
+		end = offset + limit
+	}
+	dels := make([]*types.Delegation, 0, end-offset)
+	for _, hash := range fc.pending[offset:end] {
+		dels = append(dels, fc.delegations[hash])
+	}
+
+	var nextKey []byte
+	if end < uint64(len(fc.pending)) {
+		nextKey = binary.BigEndian.AppendUint64(nil, end)
+	}
+
+	return dels, nextKey, nil
+}
+
+func (fc *FakeClientController) QueryBTCDelegation(stakingTxHash chainhash.Hash) (*types.Delegation, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if err := fc.nextQueryErr(); err != nil {
+		return nil, err
+	}
+
+	del, ok := fc.delegations[stakingTxHash]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", clientcontroller.ErrDelegationNotFound, stakingTxHash.String())
+	}
+
+	return del, nil
+}
+
+func (fc *FakeClientController) QueryStakingParams() (*types.StakingParams, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return fc.params, nil
+}
+
+func (fc *FakeClientController) QueryBtcLightClientTipHeight() (uint64, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return fc.tipHeight, nil
+}
+
+func (fc *FakeClientController) Reconnect() error {
+	if fc.inSubmit.Load() > 0 {
+		fc.overlaps.Add(1)
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.reconnects++
+
+	return nil
+}
+
+func (fc *FakeClientController) Close() error {
+	return nil
+}
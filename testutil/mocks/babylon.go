@@ -5,9 +5,11 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	types "github.com/babylonchain/covenant-emulator/types"
+	chainhash "github.com/btcsuite/btcd/chaincfg/chainhash"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -34,6 +36,21 @@ func (m *MockClientController) EXPECT() *MockClientControllerMockRecorder {
 	return m.recorder
 }
 
+// BuildCovenantSigsTx mocks base method.
+func (m *MockClientController) BuildCovenantSigsTx(covSigMsgs []*types.CovenantSigs, gasLimit uint64) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BuildCovenantSigsTx", covSigMsgs, gasLimit)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BuildCovenantSigsTx indicates an expected call of BuildCovenantSigsTx.
+func (mr *MockClientControllerMockRecorder) BuildCovenantSigsTx(covSigMsgs, gasLimit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuildCovenantSigsTx", reflect.TypeOf((*MockClientController)(nil).BuildCovenantSigsTx), covSigMsgs, gasLimit)
+}
+
 // Close mocks base method.
 func (m *MockClientController) Close() error {
 	m.ctrl.T.Helper()
@@ -48,19 +65,81 @@ func (mr *MockClientControllerMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockClientController)(nil).Close))
 }
 
+// EstimateCovenantSigsFee mocks base method.
+func (m *MockClientController) EstimateCovenantSigsFee(covSigMsgs []*types.CovenantSigs) (uint64, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EstimateCovenantSigsFee", covSigMsgs)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// EstimateCovenantSigsFee indicates an expected call of EstimateCovenantSigsFee.
+func (mr *MockClientControllerMockRecorder) EstimateCovenantSigsFee(covSigMsgs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateCovenantSigsFee", reflect.TypeOf((*MockClientController)(nil).EstimateCovenantSigsFee), covSigMsgs)
+}
+
+// QueryBTCDelegation mocks base method.
+func (m *MockClientController) QueryBTCDelegation(stakingTxHash chainhash.Hash) (*types.Delegation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryBTCDelegation", stakingTxHash)
+	ret0, _ := ret[0].(*types.Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryBTCDelegation indicates an expected call of QueryBTCDelegation.
+func (mr *MockClientControllerMockRecorder) QueryBTCDelegation(stakingTxHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryBTCDelegation", reflect.TypeOf((*MockClientController)(nil).QueryBTCDelegation), stakingTxHash)
+}
+
+// QueryBtcLightClientTipHeight mocks base method.
+func (m *MockClientController) QueryBtcLightClientTipHeight() (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryBtcLightClientTipHeight")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryBtcLightClientTipHeight indicates an expected call of QueryBtcLightClientTipHeight.
+func (mr *MockClientControllerMockRecorder) QueryBtcLightClientTipHeight() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryBtcLightClientTipHeight", reflect.TypeOf((*MockClientController)(nil).QueryBtcLightClientTipHeight))
+}
+
 // QueryPendingDelegations mocks base method.
-func (m *MockClientController) QueryPendingDelegations(limit uint64) ([]*types.Delegation, error) {
+func (m *MockClientController) QueryPendingDelegations(ctx context.Context, limit uint64) ([]*types.Delegation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "QueryPendingDelegations", limit)
+	ret := m.ctrl.Call(m, "QueryPendingDelegations", ctx, limit)
 	ret0, _ := ret[0].([]*types.Delegation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // QueryPendingDelegations indicates an expected call of QueryPendingDelegations.
-func (mr *MockClientControllerMockRecorder) QueryPendingDelegations(limit interface{}) *gomock.Call {
+func (mr *MockClientControllerMockRecorder) QueryPendingDelegations(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryPendingDelegations", reflect.TypeOf((*MockClientController)(nil).QueryPendingDelegations), ctx, limit)
+}
+
+// QueryPendingDelegationsPage mocks base method.
+func (m *MockClientController) QueryPendingDelegationsPage(ctx context.Context, limit uint64, pageKey []byte) ([]*types.Delegation, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryPendingDelegationsPage", ctx, limit, pageKey)
+	ret0, _ := ret[0].([]*types.Delegation)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// QueryPendingDelegationsPage indicates an expected call of QueryPendingDelegationsPage.
+func (mr *MockClientControllerMockRecorder) QueryPendingDelegationsPage(ctx, limit, pageKey interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryPendingDelegations", reflect.TypeOf((*MockClientController)(nil).QueryPendingDelegations), limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryPendingDelegationsPage", reflect.TypeOf((*MockClientController)(nil).QueryPendingDelegationsPage), ctx, limit, pageKey)
 }
 
 // QueryStakingParams mocks base method.
@@ -78,17 +157,31 @@ func (mr *MockClientControllerMockRecorder) QueryStakingParams() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryStakingParams", reflect.TypeOf((*MockClientController)(nil).QueryStakingParams))
 }
 
+// Reconnect mocks base method.
+func (m *MockClientController) Reconnect() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconnect")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reconnect indicates an expected call of Reconnect.
+func (mr *MockClientControllerMockRecorder) Reconnect() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconnect", reflect.TypeOf((*MockClientController)(nil).Reconnect))
+}
+
 // SubmitCovenantSigs mocks base method.
-func (m *MockClientController) SubmitCovenantSigs(covSigMsgs []*types.CovenantSigs) (*types.TxResponse, error) {
+func (m *MockClientController) SubmitCovenantSigs(ctx context.Context, covSigMsgs []*types.CovenantSigs) (*types.TxResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SubmitCovenantSigs", covSigMsgs)
+	ret := m.ctrl.Call(m, "SubmitCovenantSigs", ctx, covSigMsgs)
 	ret0, _ := ret[0].(*types.TxResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SubmitCovenantSigs indicates an expected call of SubmitCovenantSigs.
-func (mr *MockClientControllerMockRecorder) SubmitCovenantSigs(covSigMsgs interface{}) *gomock.Call {
+func (mr *MockClientControllerMockRecorder) SubmitCovenantSigs(ctx, covSigMsgs interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitCovenantSigs", reflect.TypeOf((*MockClientController)(nil).SubmitCovenantSigs), covSigMsgs)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitCovenantSigs", reflect.TypeOf((*MockClientController)(nil).SubmitCovenantSigs), ctx, covSigMsgs)
 }
@@ -9,7 +9,7 @@ import (
 	"github.com/babylonchain/covenant-emulator/types"
 )
 
-func PrepareMockedClientController(t *testing.T, params *types.StakingParams) *mocks.MockClientController {
+func PrepareMockedClientController(t testing.TB, params *types.StakingParams) *mocks.MockClientController {
 	ctl := gomock.NewController(t)
 	mockClientController := mocks.NewMockClientController(ctl)
 
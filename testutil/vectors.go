@@ -0,0 +1,169 @@
+package testutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/rand"
+	"testing"
+
+	"github.com/babylonchain/babylon/btcstaking"
+	asig "github.com/babylonchain/babylon/crypto/schnorr-adaptor-signature"
+	"github.com/babylonchain/babylon/testutil/datagen"
+	bbntypes "github.com/babylonchain/babylon/types"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/covenant-emulator/types"
+)
+
+// SigningTestVector is a delegation together with the staking params and the
+// covenant key it is signed with, and the covenant sigs expected for it. The
+// vectors are generated from fixed seeds, so that integrators can check that
+// their signing produces byte-identical sigs.
+type SigningTestVector struct {
+	Name       string
+	CovenantSk *btcec.PrivateKey
+	Params     *types.StakingParams
+	Delegation *types.Delegation
+
+	// ExpectedSlashingSigs and ExpectedSlashingUnbondingSigs are the adaptor
+	// sigs ordered as the finality provider pks of the delegation
+	ExpectedSlashingSigs          [][]byte
+	ExpectedUnbondingSig          []byte
+	ExpectedSlashingUnbondingSigs [][]byte
+}
+
+// SigningTestVectorNet is the BTC network of the signing test vectors
+var SigningTestVectorNet = &chaincfg.SimNetParams
+
+// SigningTestVectors returns the signing test vectors of delegations to a
+// single and to multiple finality providers. The expected sigs are computed
+// with the signing primitives of Babylon, independently of the emulator.
+func SigningTestVectors(t testing.TB) []*SigningTestVector {
+	return []*SigningTestVector{
+		genSigningTestVector(t, "single-fp", 1, 1),
+		genSigningTestVector(t, "multi-fp", 2, 3),
+	}
+}
+
+func genSigningTestVector(t testing.TB, name string, seed int64, fpNum int) *SigningTestVector {
+	r := rand.New(rand.NewSource(seed))
+
+	skBytes := sha256.Sum256([]byte("covenant-emulator test vector " + name))
+	covSk, covPk := btcec.PrivKeyFromBytes(skBytes[:])
+	params := GenRandomParams(r, t)
+	params.CovenantPks[0] = covPk
+
+	delSK, delPK, err := datagen.GenRandomBTCKeyPair(r)
+	require.NoError(t, err)
+	stakingTimeBlocks := uint16(5)
+	stakingValue := int64(2 * 10e8)
+	unbondingTime := uint16(params.MinimumUnbondingTime()) + 1
+	fpPks := GenBtcPublicKeys(r, t, fpNum)
+
+	stakingInfo := datagen.GenBTCStakingSlashingInfo(
+		r,
+		t,
+		SigningTestVectorNet,
+		delSK,
+		fpPks,
+		params.CovenantPks,
+		params.CovenantQuorum,
+		stakingTimeBlocks,
+		stakingValue,
+		params.SlashingAddress.String(),
+		params.SlashingRate,
+		unbondingTime,
+	)
+	stakingTxBytes, err := bbntypes.SerializeBTCTx(stakingInfo.StakingTx)
+	require.NoError(t, err)
+
+	stakingTxHash := stakingInfo.StakingTx.TxHash()
+	unbondingInfo := datagen.GenBTCUnbondingSlashingInfo(
+		r,
+		t,
+		SigningTestVectorNet,
+		delSK,
+		fpPks,
+		params.CovenantPks,
+		params.CovenantQuorum,
+		wire.NewOutPoint(&stakingTxHash, 0),
+		unbondingTime,
+		stakingValue-1000,
+		params.SlashingAddress.String(),
+		params.SlashingRate,
+		unbondingTime,
+	)
+	unbondingTxBytes, err := bbntypes.SerializeBTCTx(unbondingInfo.UnbondingTx)
+	require.NoError(t, err)
+
+	stakingSlashingPath, err := stakingInfo.StakingInfo.SlashingPathSpendInfo()
+	require.NoError(t, err)
+	stakingUnbondingPath, err := stakingInfo.StakingInfo.UnbondingPathSpendInfo()
+	require.NoError(t, err)
+	unbondingSlashingPath, err := unbondingInfo.UnbondingInfo.SlashingPathSpendInfo()
+	require.NoError(t, err)
+
+	slashingSigs := make([][]byte, 0, fpNum)
+	slashingUnbondingSigs := make([][]byte, 0, fpNum)
+	for _, fpPk := range fpPks {
+		encKey, err := asig.NewEncryptionKeyFromBTCPK(fpPk)
+		require.NoError(t, err)
+
+		slashingSig, err := stakingInfo.SlashingTx.EncSign(
+			stakingInfo.StakingTx,
+			0,
+			stakingSlashingPath.GetPkScriptPath(),
+			covSk,
+			encKey,
+		)
+		require.NoError(t, err)
+		slashingSigs = append(slashingSigs, slashingSig.MustMarshal())
+
+		slashingUnbondingSig, err := unbondingInfo.SlashingTx.EncSign(
+			unbondingInfo.UnbondingTx,
+			0,
+			unbondingSlashingPath.GetPkScriptPath(),
+			covSk,
+			encKey,
+		)
+		require.NoError(t, err)
+		slashingUnbondingSigs = append(slashingUnbondingSigs, slashingUnbondingSig.MustMarshal())
+	}
+
+	unbondingSig, err := btcstaking.SignTxWithOneScriptSpendInputStrict(
+		unbondingInfo.UnbondingTx,
+		stakingInfo.StakingTx,
+		0,
+		stakingUnbondingPath.GetPkScriptPath(),
+		covSk,
+	)
+	require.NoError(t, err)
+
+	startHeight := uint64(1000)
+	return &SigningTestVector{
+		Name:       name,
+		CovenantSk: covSk,
+		Params:     params,
+		Delegation: &types.Delegation{
+			BtcPk:            delPK,
+			FpBtcPks:         fpPks,
+			StartHeight:      startHeight,
+			EndHeight:        startHeight + uint64(stakingTimeBlocks),
+			TotalSat:         uint64(stakingValue),
+			UnbondingTime:    uint32(unbondingTime),
+			StakingTxHex:     hex.EncodeToString(stakingTxBytes),
+			StakingOutputIdx: 0,
+			SlashingTxHex:    stakingInfo.SlashingTx.ToHexStr(),
+			BtcUndelegation: &types.Undelegation{
+				UnbondingTxHex: hex.EncodeToString(unbondingTxBytes),
+				SlashingTxHex:  unbondingInfo.SlashingTx.ToHexStr(),
+			},
+		},
+		ExpectedSlashingSigs:          slashingSigs,
+		ExpectedUnbondingSig:          unbondingSig.Serialize(),
+		ExpectedSlashingUnbondingSigs: slashingUnbondingSigs,
+	}
+}
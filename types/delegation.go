@@ -1,6 +1,8 @@
 package types
 
 import (
+	"encoding/hex"
+	"fmt"
 	"math"
 
 	bbn "github.com/babylonchain/babylon/types"
@@ -102,3 +104,74 @@ type CovenantSchnorrSigInfo struct {
 	Pk  *btcec.PublicKey
 	Sig *schnorr.Signature
 }
+
+// DelegationJSON is the JSON representation of a delegation that is used to
+// feed delegations to the covenant emulator from outside the consumer chain,
+// e.g., a file or stdin. Public keys are hex-encoded in BIP-340 format.
+type DelegationJSON struct {
+	BtcPk                  string   `json:"btc_pk"`
+	FpBtcPks               []string `json:"fp_btc_pk_list"`
+	StartHeight            uint64   `json:"start_height"`
+	EndHeight              uint64   `json:"end_height"`
+	TotalSat               uint64   `json:"total_sat"`
+	StakingTxHex           string   `json:"staking_tx_hex"`
+	StakingOutputIdx       uint32   `json:"staking_output_idx"`
+	SlashingTxHex          string   `json:"slashing_tx_hex"`
+	UnbondingTime          uint32   `json:"unbonding_time"`
+	UnbondingTxHex         string   `json:"unbonding_tx_hex"`
+	UnbondingSlashingTxHex string   `json:"unbonding_slashing_tx_hex"`
+}
+
+// ToDelegation converts the JSON representation into a delegation
+// without any covenant signatures
+func (dj *DelegationJSON) ToDelegation() (*Delegation, error) {
+	btcPk, err := parseBIP340PubKeyHex(dj.BtcPk)
+	if err != nil {
+		return nil, fmt.Errorf("invalid btc_pk: %w", err)
+	}
+
+	if len(dj.FpBtcPks) == 0 {
+		return nil, fmt.Errorf("empty fp_btc_pk_list")
+	}
+	fpPks := make([]*btcec.PublicKey, 0, len(dj.FpBtcPks))
+	for i, pkHex := range dj.FpBtcPks {
+		fpPk, err := parseBIP340PubKeyHex(pkHex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fp_btc_pk_list[%d]: %w", i, err)
+		}
+		fpPks = append(fpPks, fpPk)
+	}
+
+	if dj.EndHeight < dj.StartHeight || dj.EndHeight-dj.StartHeight > math.MaxUint16 {
+		return nil, fmt.Errorf("invalid staking period [%d, %d]", dj.StartHeight, dj.EndHeight)
+	}
+
+	if dj.UnbondingTxHex == "" || dj.UnbondingSlashingTxHex == "" {
+		return nil, fmt.Errorf("empty undelegation")
+	}
+
+	return &Delegation{
+		BtcPk:            btcPk,
+		FpBtcPks:         fpPks,
+		StartHeight:      dj.StartHeight,
+		EndHeight:        dj.EndHeight,
+		TotalSat:         dj.TotalSat,
+		StakingTxHex:     dj.StakingTxHex,
+		StakingOutputIdx: dj.StakingOutputIdx,
+		SlashingTxHex:    dj.SlashingTxHex,
+		UnbondingTime:    dj.UnbondingTime,
+		BtcUndelegation: &Undelegation{
+			UnbondingTxHex: dj.UnbondingTxHex,
+			SlashingTxHex:  dj.UnbondingSlashingTxHex,
+		},
+	}, nil
+}
+
+func parseBIP340PubKeyHex(pkHex string) (*btcec.PublicKey, error) {
+	pkBytes, err := hex.DecodeString(pkHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return schnorr.ParsePubKey(pkBytes)
+}
@@ -1,9 +1,13 @@
 package types
 
 import (
+	"bytes"
+
 	sdkmath "cosmossdk.io/math"
 	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
 )
 
 type StakingParams struct {
@@ -32,6 +36,10 @@ type StakingParams struct {
 
 	// The minimum time for unbonding transaction timelock in BTC blocks
 	MinUnbondingTime uint32
+
+	// BTCNetParams is the BTC network of the consumer chain of the params,
+	// which is nil if the chain uses the configured network of the emulator
+	BTCNetParams *chaincfg.Params
 }
 
 // MinimumUnbondingTime returns the minimum unbonding time. It is the bigger value from:
@@ -43,3 +51,43 @@ func (p *StakingParams) MinimumUnbondingTime() uint64 {
 		p.FinalizationTimeoutBlocks,
 	)
 }
+
+// Equal returns whether the two params are the same
+func (p *StakingParams) Equal(other *StakingParams) bool {
+	if p == nil || other == nil {
+		return p == other
+	}
+
+	if p.ComfirmationTimeBlocks != other.ComfirmationTimeBlocks ||
+		p.FinalizationTimeoutBlocks != other.FinalizationTimeoutBlocks ||
+		p.MinSlashingTxFeeSat != other.MinSlashingTxFeeSat ||
+		p.CovenantQuorum != other.CovenantQuorum ||
+		p.MinUnbondingTime != other.MinUnbondingTime {
+		return false
+	}
+
+	if (p.SlashingAddress == nil) != (other.SlashingAddress == nil) ||
+		(p.SlashingAddress != nil && p.SlashingAddress.EncodeAddress() != other.SlashingAddress.EncodeAddress()) {
+		return false
+	}
+
+	if (p.BTCNetParams == nil) != (other.BTCNetParams == nil) ||
+		(p.BTCNetParams != nil && p.BTCNetParams.Name != other.BTCNetParams.Name) {
+		return false
+	}
+
+	if !p.SlashingRate.Equal(other.SlashingRate) || !p.MinComissionRate.Equal(other.MinComissionRate) {
+		return false
+	}
+
+	if len(p.CovenantPks) != len(other.CovenantPks) {
+		return false
+	}
+	for i := range p.CovenantPks {
+		if !bytes.Equal(schnorr.SerializePubKey(p.CovenantPks[i]), schnorr.SerializePubKey(other.CovenantPks[i])) {
+			return false
+		}
+	}
+
+	return true
+}
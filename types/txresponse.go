@@ -6,5 +6,11 @@ import (
 
 type TxResponse struct {
 	TxHash string
-	Events []provider.RelayerEvent
+	// Height is the height of the block including the tx
+	Height int64
+	// Code is the result code of the tx, 0 means success
+	Code      uint32
+	GasWanted int64
+	GasUsed   int64
+	Events    []provider.RelayerEvent
 }